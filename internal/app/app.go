@@ -6,13 +6,16 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"os"
 	"strconv"
 	"strings"
 	"time"
 
 	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
 	"github.com/balaji01-4d/pgxcli/internal/app/commands"
 	"github.com/balaji01-4d/pgxcli/internal/app/renderer"
 	"github.com/balaji01-4d/pgxcli/internal/app/ui"
@@ -21,8 +24,10 @@ import (
 	"github.com/balaji01-4d/pgxcli/internal/config"
 	"github.com/balaji01-4d/pgxcli/internal/database"
 	"github.com/balaji01-4d/pgxcli/internal/database/result"
+	"github.com/balaji01-4d/pgxcli/internal/history"
 	"github.com/balaji01-4d/pgxcli/internal/parser"
 	"github.com/balaji01-4d/pgxspecial"
+	"github.com/charmbracelet/x/term"
 )
 
 // Application defines the interface for the main application logic.
@@ -46,25 +51,73 @@ type pgxCLI struct {
 	config    *config.Config
 	logger    *slog.Logger
 	completer *completer.Completer
+
+	// historyFile is where history entries are kept, empty when they are not.
+	historyFile string
+
+	// plans holds the last two plans captured by \explain, oldest first,
+	// for \explain diff.
+	plans []*renderer.Plan
+
+	// profileTag names the connection profile in use on the status bar,
+	// empty when the session was not started from a profile.
+	profileTag string
+	profile    string
+
+	// overrides are the settings changed with \config, which keep their
+	// session value when the config file is reloaded.
+	overrides map[string]bool
 }
 
-func New(cfg *config.Config, printer cliio.Printer, logger *slog.Logger, completer *completer.Completer) (Application, error) {
+// New creates the application. profile is the name of the connection
+// profile the session was opened with, or empty.
+func New(cfg *config.Config, printer cliio.Printer, logger *slog.Logger, completer *completer.Completer, profile string) (Application, error) {
 	return &pgxCLI{
-		config:    cfg,
-		logger:    logger,
-		Printer:   printer,
-		completer: completer,
+		config:     cfg,
+		logger:     logger,
+		Printer:    printer,
+		completer:  completer,
+		profileTag: profileTag(cfg, profile),
+		profile:    profile,
+		overrides:  make(map[string]bool),
 	}, nil
 }
 
+// profileTag renders the status bar tag of profile in the color it is
+// configured with.
+func profileTag(cfg *config.Config, profile string) string {
+	if profile == "" {
+		return ""
+	}
+	tag := "[" + profile + "]"
+	connection, _ := cfg.Profile(profile)
+	if code := connection.Color.Code(); code != "" {
+		tag = lipgloss.NewStyle().Foreground(lipgloss.Color(code)).Bold(true).Render(tag)
+	}
+	return tag
+}
+
 func (p *pgxCLI) execute(ctx context.Context, client *database.Client, query string) tea.Cmd {
+	// outcome is written by the command below and read by promptReady,
+	// which tea.Sequence always runs afterwards.
+	var outcome ui.Outcome
 	promptReady := func() tea.Msg {
-		prefix := client.ParsePrompt(p.config.Main.Prompt)
-		return ui.ReadyMsg{Prefix: prefix} // this is used to unblock input after executing a command
+		return p.readyMsg(ctx, client, outcome) // this is used to unblock input after executing a command
 	}
 
 	p.logger.Debug("received command", "command_length", len(query))
 
+	// the connection is needed for the command, so stop waiting for notifications
+	client.StopListening()
+
+	// captured before running so the command goroutine never reads the model
+	prevQuery := p.model.PrevUserInput()
+	// "SELECT ... $1 \parse name" prepares the query typed before \parse
+	// without running it, as psql does.
+	if stmt, meta := parser.SplitMetaCommand(query); stmt != "" && isParseCommand(meta) {
+		query, prevQuery = meta, stmt
+	}
+
 	if cmd, ok := builtinsCommand[query]; ok {
 		p.logger.Debug("executing builtin command", "command", query)
 		cmd()
@@ -75,64 +128,190 @@ func (p *pgxCLI) execute(ctx context.Context, client *database.Client, query str
 		metaResult, okay, err := client.ExecuteSpecial(ctx, query)
 		if err != nil {
 			p.logger.Error("error executing special command", "error", err)
+			outcome.Err = err
 			return ui.ExecCmdMsg{Cmd: tea.Sequence(p.printError(err), promptReady)}
 		}
 		if okay {
-			start := time.Now()
-			p.logger.Debug("special command executed", "result_kind", metaResult.ResultKind())
-			result, quit, err := p.handleSpecialCommand(ctx, metaResult, client)
-			if quit {
-				p.logger.Info("REPL exiting via quit command")
-				return ui.ExecCmdMsg{Cmd: tea.Quit}
+			switch action := metaResult.(type) {
+			case database.WatchAction:
+				return p.watch(ctx, client, prevQuery, action, promptReady)
+			case database.ImportAction:
+				return p.importCSV(ctx, client, action, promptReady)
+			case database.ParseAction:
+				return p.parseStatement(ctx, client, prevQuery, action, promptReady)
+			case database.BindAction:
+				// \bind only sets the parameters of the next query, so like
+				// psql it prints nothing, not even the timing
+				client.Bind(action.Values)
+				return ui.ExecCmdMsg{Cmd: promptReady}
 			}
 
-			if err != nil {
-				p.logger.Error("error handling special command", "error", err)
-				errCmd := p.printError(err)
-				return ui.ExecCmdMsg{Cmd: tea.Sequence(errCmd, promptReady)}
+			runSpecial := func() tea.Msg {
+				start := time.Now()
+				p.logger.Debug("special command executed", "result_kind", metaResult.ResultKind())
+				result, quit, err := p.handleSpecialCommand(ctx, metaResult, client)
+				if quit {
+					p.logger.Info("REPL exiting via quit command")
+					return ui.ExecCmdMsg{Cmd: tea.Quit}
+				}
+
+				if err != nil {
+					p.logger.Error("error handling special command", "error", err)
+					outcome.Err = err
+					errCmd := p.printError(err)
+					return ui.ExecCmdMsg{Cmd: tea.Sequence(errCmd, promptReady)}
+				}
+				execTime := time.Since(start)
+				outcome.Duration = execTime
+				timingInfo := fmt.Sprintf("Time %.3fs", execTime.Seconds())
+				return ui.ExecCmdMsg{Cmd: tea.Sequence(
+					p.printViaPager(result+timingInfo),
+					promptReady,
+				)}
+			}
+
+			if warning := transactionWarning(client); warning != "" && endsSession(metaResult) {
+				p.logger.Warn("confirming command with open transaction", "result_kind", metaResult.ResultKind())
+				return ui.ConfirmMsg{
+					Question:  warning + " Continue? [y/N]",
+					OnConfirm: runSpecial,
+					OnCancel:  promptReady,
+				}
 			}
-			execTime := time.Since(start)
-			timingInfo := fmt.Sprintf("Time %.3fs", execTime.Seconds())
-			return ui.ExecCmdMsg{Cmd: tea.Sequence(
-				p.printViaPager(result+timingInfo),
-				promptReady,
-			)}
+			return runSpecial()
 		}
 
 		p.logger.Debug("executing query")
-		stmts := parser.SplitSQLStatements(query)
-		cmds := make([]tea.Cmd, 0, len(stmts)+1) // +1 for prompt ready
-
-	StatementsLoop:
-		for _, stmt := range stmts {
-			p.logger.Debug("parsed statement", "statement", stmt)
-			if stmt == "" || stmt == ";" {
-				continue
-			}
+		return p.runQuery(ctx, client, parser.SplitSQLStatements(query), &outcome, promptReady)
+	}
+}
 
-			queryResult, err := client.ExecuteQuery(ctx, stmt)
-			if err != nil {
-				p.logger.Error("query execution failed", "error", err)
-				cmds = append(cmds, p.printError(err))
-				if p.config.Main.OnError == config.OnErrorStop {
-					break StatementsLoop
+// runQuery runs stmts and then hands the prompt back. When a statement uses
+// $n placeholders without \bind values, it stops there to ask for them.
+func (p *pgxCLI) runQuery(ctx context.Context, client *database.Client, stmts []string, outcome *ui.Outcome, promptReady tea.Cmd) tea.Msg {
+	cmds, rest := p.runStatements(ctx, client, stmts, outcome)
+	if len(rest) == 0 {
+		cmds = append(cmds, promptReady)
+	} else {
+		cmds = append(cmds, func() tea.Msg {
+			return p.askParams(ctx, client, rest, outcome, promptReady)
+		})
+	}
+	return ui.ExecCmdMsg{Cmd: tea.Sequence(cmds...)}
+}
+
+// askParams prompts for each placeholder of stmts[0], binds the answers and
+// resumes running stmts.
+func (p *pgxCLI) askParams(ctx context.Context, client *database.Client, stmts []string, outcome *ui.Outcome, promptReady tea.Cmd) tea.Msg {
+	n := parser.Placeholders(stmts[0])
+	values := make([]string, 0, n)
+
+	var ask func() tea.Msg
+	ask = func() tea.Msg {
+		return ui.PromptMsg{
+			Question: fmt.Sprintf("Value for $%d", len(values)+1),
+			OnSubmit: func(value string) tea.Cmd {
+				values = append(values, value)
+				if len(values) < n {
+					return ask
 				}
-				continue
-			}
-			resultCmd, err := p.handleQueryResult(queryResult)
-			if err != nil {
-				p.logger.Error("error handling query result", "error", err)
-				cmds = append(cmds, p.printError(err))
-				if p.config.Main.OnError == config.OnErrorStop {
-					break StatementsLoop
+				return func() tea.Msg {
+					client.Bind(values)
+					return p.runQuery(ctx, client, stmts, outcome, promptReady)
 				}
-				continue
+			},
+			OnCancel: promptReady,
+		}
+	}
+	return ask()
+}
+
+// runStatements executes each statement, honoring on_error, and returns the
+// commands printing their results. The query time, row counts and errors are
+// added to outcome. It stops before a statement that needs parameter values,
+// returning it and the statements after it.
+func (p *pgxCLI) runStatements(ctx context.Context, client *database.Client, stmts []string, outcome *ui.Outcome) ([]tea.Cmd, []string) {
+	cmds := make([]tea.Cmd, 0, len(stmts)+1) // +1 for prompt ready
+
+	for i, stmt := range stmts {
+		p.logger.Debug("parsed statement", "statement", stmt)
+		if stmt == "" || stmt == ";" {
+			continue
+		}
+		if !client.HasBind() && parser.Placeholders(stmt) > 0 {
+			return cmds, stmts[i:]
+		}
+
+		queryResult, err := client.ExecuteQuery(ctx, stmt)
+		if err != nil {
+			p.logger.Error("query execution failed", "error", err)
+			outcome.Err = err
+			cmds = append(cmds, p.printError(err))
+			if p.stopOnError(err) {
+				break
 			}
-			cmds = append(cmds, resultCmd)
+			continue
 		}
-		cmds = append(cmds, promptReady)
+		res, isQuery := queryResult.(*result.QueryResult)
+		if isQuery {
+			outcome.Duration += res.Duration()
+		}
+		resultCmd, err := p.handleQueryResult(queryResult)
+		if err != nil {
+			err = client.RecoverConnection(ctx, err)
+			p.logger.Error("error handling query result", "error", err)
+			outcome.Err = err
+			cmds = append(cmds, p.printError(err))
+			if p.stopOnError(err) {
+				break
+			}
+			continue
+		}
+		if isQuery {
+			outcome.Rows += res.RowsAffected()
+		}
+		cmds = append(cmds, resultCmd)
+	}
+	return cmds, nil
+}
 
-		return ui.ExecCmdMsg{Cmd: tea.Sequence(cmds...)}
+// stopOnError reports whether the remaining statements should be skipped
+// after err. A lost connection always stops, since the session they were
+// written for is gone.
+func (p *pgxCLI) stopOnError(err error) bool {
+	var reconnectErr *database.ReconnectError
+	if errors.As(err, &reconnectErr) {
+		return true
+	}
+	return p.config.Main.OnError == config.OnErrorStop
+}
+
+// readyMsg hands the prompt back to the user. If a reconnect was refused for
+// lack of a password, the user is asked for one first.
+func (p *pgxCLI) readyMsg(ctx context.Context, client *database.Client, outcome ui.Outcome) tea.Msg {
+	ready := func() tea.Msg {
+		prefix := client.ParsePrompt(p.config.Main.Prompt)
+		status := p.statusLine(client, outcome.Duration)
+		client.StartListening(p.notify)
+		return ui.ReadyMsg{Prefix: prefix, Status: status, Session: session(client), Outcome: outcome}
+	}
+	if !client.AwaitingPassword() {
+		return ready()
+	}
+
+	return ui.PromptMsg{
+		Question: fmt.Sprintf("Password for user %s", client.GetUser()),
+		Secret:   true,
+		OnSubmit: func(password string) tea.Cmd {
+			return func() tea.Msg {
+				if err := client.Reconnect(ctx, password); err != nil {
+					p.logger.Error("reconnect failed", "error", err)
+					return ui.ExecCmdMsg{Cmd: tea.Sequence(p.printError(err), ready)}
+				}
+				return ui.ExecCmdMsg{Cmd: tea.Sequence(ui.PrintCmd("Reconnected."), ready)}
+			}
+		},
+		OnCancel: ready,
 	}
 }
 
@@ -141,15 +320,39 @@ func (p *pgxCLI) Start(ctx context.Context, client *database.Client) error {
 		return p.execute(ctx, client, query)
 	}
 
+	client.SetAutocommit(p.config.Main.Autocommit)
+	client.SetOnErrorRollback(onErrorRollbackEnabled(p.config.Main.OnErrorRollback))
+
 	initialPrefix := client.ParsePrompt(p.config.Main.Prompt)
-	m, err := ui.New(initialPrefix, p.completer.GetKeyWords(), p.config.Main.HistoryFile, string(p.config.Main.Style), executeFunc)
+	initialStatus := p.statusLine(client, 0)
+	p.historyFile = history.Path(p.config.Main.HistoryFile)
+	m, err := ui.New(initialPrefix, initialStatus, p.completer.GetKeyWords(), p.historyFile, string(p.config.Main.Style), p.config.Keys, p.config.Main.ViMode, executeFunc)
 	if err != nil {
 		return fmt.Errorf("creating UI model: %w", err)
 	}
 
+	redactor, err := parser.NewRedactor(p.config.Main.RedactPatterns)
+	if err != nil {
+		return err
+	}
+	m.SetHistoryOptions(ui.HistoryOptions{
+		Scope:       p.config.Main.HistoryScope,
+		IgnoreSpace: p.config.Main.HistoryIgnoreSpace,
+		Redact:      redactor.Redact,
+	})
+	m.SetSession(session(client))
 	p.model = m
 	p.program = tea.NewProgram(p.model, tea.WithContext(ctx))
 
+	watchCtx, stopWatching := context.WithCancel(ctx)
+	defer stopWatching()
+	err = config.Watch(watchCtx, func(cfg *config.Config, err error) {
+		p.configChanged(client, cfg, err)
+	})
+	if err != nil {
+		p.logger.Warn("config file changes will not be applied", "error", err)
+	}
+
 	if _, err := p.program.Run(); err != nil {
 		return fmt.Errorf("running UI program: %w", err)
 	}
@@ -157,6 +360,77 @@ func (p *pgxCLI) Start(ctx context.Context, client *database.Client) error {
 	return nil
 }
 
+// session describes the connection for the history entries of the queries
+// run on it.
+func session(client *database.Client) ui.Session {
+	return ui.Session{Host: client.GetHost(), Database: client.GetDatabase(), User: client.GetUser()}
+}
+
+// onErrorRollbackEnabled resolves the on_error_rollback mode for this session.
+func onErrorRollbackEnabled(mode config.OnErrorRollback) bool {
+	switch mode {
+	case config.OnErrorRollbackOn:
+		return true
+	case config.OnErrorRollbackInteractive:
+		return term.IsTerminal(os.Stdin.Fd())
+	default:
+		return false
+	}
+}
+
+// transactionWarning describes the transaction that would be lost if the
+// session ended now, or returns an empty string when none is open.
+func transactionWarning(client *database.Client) string {
+	switch client.Status().TxStatus {
+	case database.TxActive:
+		return "A transaction is in progress and will be rolled back."
+	case database.TxFailed:
+		return "The current transaction is aborted and will be rolled back."
+	default:
+		return ""
+	}
+}
+
+// endsSession reports whether a special command closes the current connection.
+func endsSession(metaResult pgxspecial.SpecialCommandResult) bool {
+	switch action := metaResult.(type) {
+	case database.ExitAction:
+		return true
+	case database.ChangeDbAction:
+		return action.Name != ""
+	default:
+		return false
+	}
+}
+
+// statusLine describes the live session for the status bar.
+func (p *pgxCLI) statusLine(client *database.Client, elapsed time.Duration) string {
+	status := client.Status()
+	parts := []string{"pgxcli", status.TxStatus.String()}
+	if p.profileTag != "" {
+		parts[0] = "pgxcli " + p.profileTag
+	}
+	if status.PID != 0 {
+		parts = append(parts, fmt.Sprintf("pid %d", status.PID))
+	}
+	if status.ServerVersion != "" {
+		parts = append(parts, "PostgreSQL "+status.ServerVersion)
+	}
+	if status.Superuser {
+		parts = append(parts, "superuser")
+	}
+	if status.ReadOnly {
+		parts = append(parts, "read-only")
+	}
+	if !client.Autocommit() {
+		parts = append(parts, "autocommit off")
+	}
+	if elapsed > 0 {
+		parts = append(parts, fmt.Sprintf("last %.3fs", elapsed.Seconds()))
+	}
+	return strings.Join(parts, " · ")
+}
+
 func (p *pgxCLI) handleSpecialCommand(ctx context.Context, metaResult pgxspecial.SpecialCommandResult, client *database.Client) (string, bool, error) {
 	switch metaResult.ResultKind() {
 
@@ -177,24 +451,7 @@ func (p *pgxCLI) handleSpecialCommand(ctx context.Context, metaResult pgxspecial
 		), false, nil
 
 	case database.Conninfo:
-		var host string
-		if strings.HasPrefix(client.GetHost(), "/") {
-			host = fmt.Sprintf("Socket %q", client.GetHost())
-		} else {
-			host = fmt.Sprintf("Host %q", client.GetHost())
-		}
-
-		var port string
-		if client.GetPort() == 0 {
-			port = "None"
-		} else {
-			port = strconv.Itoa(int(client.GetPort()))
-		}
-
-		return fmt.Sprintf(
-			"You are connected to database %q as user %q on %s at port %s",
-			client.GetDatabase(), client.GetUser(), host, port,
-		), false, nil
+		return connInfo(client), false, nil
 
 	case pgxspecial.ResultKindRows:
 		table, err := renderer.RowsResult(metaResult, p.config)
@@ -218,20 +475,328 @@ func (p *pgxCLI) handleSpecialCommand(ctx context.Context, metaResult pgxspecial
 		}
 		return tables, false, nil
 
+	default:
+		return p.handleSessionCommand(ctx, metaResult, client)
+	}
+}
+
+// explain renders a captured plan and remembers it, or compares the last two
+// captured plans for \explain diff.
+func (p *pgxCLI) explain(action database.ExplainAction) (string, bool, error) {
+	if action.Diff {
+		if len(p.plans) < 2 {
+			return "", false, errors.New("\\explain diff: run \\explain twice to capture two plans first")
+		}
+		return renderer.ExplainDiff(p.plans[0], p.plans[1], p.config), false, nil
+	}
+
+	plan, err := renderer.ParsePlan(action.Plan)
+	if err != nil {
+		return "", false, err
+	}
+	p.plans = append(p.plans, plan)
+	if len(p.plans) > 2 {
+		p.plans = p.plans[len(p.plans)-2:]
+	}
+	return renderer.ExplainResult(plan, p.config), false, nil
+}
+
+// listHistory renders the entries of the history file matching the \history
+// pattern, limited to the history_scope of the connection unless the global
+// history was asked for.
+func (p *pgxCLI) listHistory(client *database.Client, action database.HistoryAction) (string, bool, error) {
+	if p.historyFile == "" {
+		return "", false, errors.New("\\history: no history file is available")
+	}
+	entries, err := history.Load(p.historyFile)
+	if err != nil {
+		return "", false, err
+	}
+	if !action.Global {
+		entries = history.Scoped(entries, p.config.Main.HistoryScope, client.GetHost(), client.GetDatabase())
+	}
+	output, err := renderer.HistoryResult(history.Filter(entries, action.Pattern), p.config)
+	return output, false, err
+}
+
+// handleSessionCommand handles pgxcli's own commands that act on the session.
+func (p *pgxCLI) handleSessionCommand(ctx context.Context, metaResult pgxspecial.SpecialCommandResult, client *database.Client) (string, bool, error) {
+	switch action := metaResult.(type) {
+	case database.CopyAction:
+		return p.copy(ctx, client, action)
+
+	case database.BindNamedAction:
+		res, err := client.ExecutePrepared(ctx, action.Name, action.Values)
+		if err != nil {
+			return "", false, err
+		}
+		output, err := p.renderTable(res)
+		if err != nil {
+			return "", false, client.RecoverConnection(ctx, err)
+		}
+		return output + "\n", false, nil
+
+	case database.ExplainAction:
+		return p.explain(action)
+
+	case database.HistoryAction:
+		return p.listHistory(client, action)
+
+	case database.ConfigAction:
+		return p.configCommand(client, action)
+
+	case database.ClosePreparedAction:
+		return "DEALLOCATE\n", false, client.ClosePrepared(ctx, action.Name)
+
+	case database.ListenAction:
+		if action.Listen {
+			return "LISTEN\n", false, client.Listen(ctx, action.Channel)
+		}
+		return "UNLISTEN\n", false, client.Unlisten(ctx, action.Channel)
+
 	default:
 		return "", false, nil
 	}
 }
 
+// copy runs a client-side \copy. Progress is shown in the status bar; when
+// copying from stdin or to stdout the terminal is handed over for the
+// duration of the copy instead.
+func (p *pgxCLI) copy(ctx context.Context, client *database.Client, action database.CopyAction) (string, bool, error) {
+	progress := func(cp database.CopyProgress) {
+		p.program.Send(ui.ProgressMsg{Text: "copying " + cp.String()})
+	}
+
+	if action.Stdio() {
+		progress = nil
+		if err := p.program.ReleaseTerminal(); err != nil {
+			return "", false, fmt.Errorf("releasing terminal: %w", err)
+		}
+		defer func() {
+			if err := p.program.RestoreTerminal(); err != nil {
+				p.logger.Error("failed to restore terminal", "error", err)
+			}
+		}()
+		if action.From {
+			fmt.Fprintln(os.Stderr, "Enter data to be copied followed by a newline.")
+			fmt.Fprintln(os.Stderr, "End with a backslash and a period on a line by itself, or an EOF signal.")
+		}
+	}
+
+	res, err := client.Copy(ctx, action, os.Stdin, os.Stdout, progress)
+	if err != nil {
+		return "", false, err
+	}
+	p.logger.Info("copy finished", "rows", res.Rows, "bytes", res.Bytes)
+	return fmt.Sprintf("COPY %d (%s)\n", res.Rows, database.FormatBytes(res.Bytes)), false, nil
+}
+
+// connInfo describes the current connection for \conninfo.
+func connInfo(client *database.Client) string {
+	var host string
+	if strings.HasPrefix(client.GetHost(), "/") {
+		host = fmt.Sprintf("Socket %q", client.GetHost())
+	} else {
+		host = fmt.Sprintf("Host %q", client.GetHost())
+		if addr := client.GetHostAddr(); addr != "" && addr != client.GetHost() {
+			host += fmt.Sprintf(" (address %q)", addr)
+		}
+	}
+
+	var port string
+	if client.GetPort() == 0 {
+		port = "None"
+	} else {
+		port = strconv.Itoa(int(client.GetPort()))
+	}
+
+	info := fmt.Sprintf(
+		"You are connected to database %q as user %q on %s at port %s",
+		client.GetDatabase(), client.GetUser(), host, port,
+	)
+	if status, ok := client.TLS(); ok {
+		info += fmt.Sprintf(
+			"\nSSL connection (protocol: %s, cipher: %s, server certificate: %s)",
+			status.Version, status.Cipher, status.Verification,
+		)
+	}
+	return info
+}
+
+// isParseCommand reports whether meta is a \parse command.
+func isParseCommand(meta string) bool {
+	fields := strings.Fields(meta)
+	return len(fields) > 0 && fields[0] == "\\parse"
+}
+
+// parseStatement prepares query as a named statement for \parse: the query
+// typed before \parse on the same input, or else the previous query.
+func (p *pgxCLI) parseStatement(ctx context.Context, client *database.Client, query string, action database.ParseAction, promptReady tea.Cmd) tea.Msg {
+	if err := client.Prepare(ctx, action.Name, query); err != nil {
+		p.logger.Error("prepare failed", "statement", action.Name, "error", err)
+		return ui.ExecCmdMsg{Cmd: tea.Sequence(p.printError(err), promptReady)}
+	}
+	return ui.ExecCmdMsg{Cmd: tea.Sequence(ui.PrintCmd("PREPARE"), promptReady)}
+}
+
+// notify prints a notification above the prompt without disturbing the
+// input being typed.
+func (p *pgxCLI) notify(n database.Notification) {
+	p.logger.Debug("notification received", "channel", n.Channel, "pid", n.PID)
+	p.program.Send(ui.ExecCmdMsg{Cmd: ui.PrintCmd(n.String())})
+}
+
+// importCSV proposes a table inferred from the CSV file and, once the user
+// confirms the CREATE TABLE statement, creates it and loads the file.
+func (p *pgxCLI) importCSV(ctx context.Context, client *database.Client, action database.ImportAction, promptReady tea.Cmd) tea.Msg {
+	plan, err := database.PlanImport(action)
+	if err != nil {
+		return ui.ExecCmdMsg{Cmd: tea.Sequence(p.printError(err), promptReady)}
+	}
+
+	load := func() tea.Msg {
+		progress := func(cp database.CopyProgress) {
+			p.program.Send(ui.ProgressMsg{Text: "importing " + cp.String()})
+		}
+		res, err := client.Import(ctx, plan, progress)
+		if err != nil {
+			p.logger.Error("import failed", "table", plan.Table, "error", err)
+			return ui.ExecCmdMsg{Cmd: tea.Sequence(p.printError(err), promptReady)}
+		}
+		return ui.ExecCmdMsg{Cmd: tea.Sequence(ui.PrintCmd(importSummary(plan, res)), promptReady)}
+	}
+
+	question := fmt.Sprintf("%s;\nTypes inferred from %d sampled rows. Create table %s and load %s? [y/N]",
+		plan.CreateTable(), plan.Sampled, plan.Table, plan.Path)
+	return ui.ConfirmMsg{Question: question, OnConfirm: load, OnCancel: promptReady}
+}
+
+func importSummary(plan database.ImportPlan, res database.ImportResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "IMPORT %d rows into %s", res.Rows, plan.Table)
+	if res.Rejected == 0 {
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "\n%d rows rejected:", res.Rejected)
+	for _, row := range res.Samples {
+		fmt.Fprintf(&b, "\n  line %d: %v", row.Line, row.Err)
+	}
+	if more := res.Rejected - len(res.Samples); more > 0 {
+		fmt.Fprintf(&b, "\n  ... and %d more", more)
+	}
+	return b.String()
+}
+
+// watch starts re-running query on the interval requested by \watch.
+// Output is redrawn in place by the ui, so the pager is never used.
+func (p *pgxCLI) watch(ctx context.Context, client *database.Client, query string, action database.WatchAction, promptReady tea.Cmd) tea.Msg {
+	if strings.TrimSpace(query) == "" {
+		err := errors.New("\\watch cannot be used with an empty query")
+		return ui.ExecCmdMsg{Cmd: tea.Sequence(p.printError(err), promptReady)}
+	}
+
+	p.logger.Debug("starting watch", "interval", action.Interval, "count", action.Count)
+	// canceled when the watch is stopped, so a running iteration ends before
+	// the prompt comes back
+	watchCtx, stop := context.WithCancel(database.CancelOnServer(ctx))
+	return ui.WatchMsg{
+		Interval: action.Interval,
+		Count:    action.Count,
+		Run: func() (string, error) {
+			return p.runWatchQuery(watchCtx, client, query)
+		},
+		Stop: stop,
+		// resumes listening for notifications and refreshes the status line
+		Done: promptReady,
+	}
+}
+
+func (p *pgxCLI) runWatchQuery(ctx context.Context, client *database.Client, query string) (string, error) {
+	outputs := make([]string, 0, 1)
+	for _, stmt := range parser.SplitSQLStatements(query) {
+		if stmt == "" || stmt == ";" {
+			continue
+		}
+
+		queryResult, err := client.ExecuteQuery(ctx, stmt)
+		if err != nil {
+			return "", err
+		}
+		output, err := p.renderQueryResult(queryResult)
+		if err != nil {
+			return "", client.RecoverConnection(ctx, err)
+		}
+		outputs = append(outputs, output)
+	}
+	return strings.Join(outputs, "\n"), nil
+}
+
 func (p *pgxCLI) handleQueryResult(r result.Result) (tea.Cmd, error) {
+	res, isQuery := r.(*result.QueryResult)
+	builtin := isQuery && p.Printer.BuiltinPager()
+	if builtin {
+		// the viewer needs the rows again after the table is rendered
+		if err := res.Materialize(); err != nil {
+			return nil, err
+		}
+	}
+
+	output, err := p.renderQueryResult(r)
+	if err != nil {
+		return nil, err
+	}
+	if builtin && len(res.Columns()) > 0 && p.Printer.ShouldUsePager(output) {
+		return p.viewResult(res)
+	}
+	return p.printViaPager(output), nil
+}
+
+// viewResult opens a materialized result in the built-in viewer. The
+// command tag and timing are printed once it is closed.
+func (p *pgxCLI) viewResult(res *result.QueryResult) (tea.Cmd, error) {
+	rows, err := res.Rows()
+	if err != nil {
+		return nil, err
+	}
+
+	cells := make([][]string, len(rows))
+	for i, row := range rows {
+		cells[i] = make([]string, len(row))
+		for j, v := range row {
+			cells[i][j] = renderer.CellText(v)
+		}
+	}
+	return ui.ShowViewerCmd(ui.ViewerMsg{
+		Columns: res.Columns(),
+		Rows:    cells,
+		Footer:  fmt.Sprintf("%s\nTime %.3fs", res.CommandTag(), res.Duration().Seconds()),
+	}), nil
+}
+
+func (p *pgxCLI) renderQueryResult(r result.Result) (string, error) {
+	output, err := p.renderTable(r)
+	if err != nil {
+		return "", err
+	}
+
+	// Append timing info to the output
+	timingInfo := fmt.Sprintf("\nTime %.3fs", r.(*result.QueryResult).Duration().Seconds())
+	output += timingInfo
+
+	return output, nil
+}
+
+// renderTable renders the rows and command tag of a query result.
+func (p *pgxCLI) renderTable(r result.Result) (string, error) {
 	res, ok := r.(*result.QueryResult)
 	if !ok {
-		return nil, fmt.Errorf("unsupported query result type: %T", r)
+		return "", fmt.Errorf("unsupported query result type: %T", r)
 	}
 
 	var s strings.Builder
 	if err := renderer.Table(res, &s, p.config); err != nil {
-		return nil, err
+		return "", err
 	}
 
 	output := s.String()
@@ -240,16 +805,14 @@ func (p *pgxCLI) handleQueryResult(r result.Result) (tea.Cmd, error) {
 	} else {
 		output += res.CommandTag()
 	}
-
-	// Append timing info to the output
-	timingInfo := fmt.Sprintf("\nTime %.3fs", res.Duration().Seconds())
-	output += timingInfo
-
-	return p.printViaPager(output), nil
+	return output, nil
 }
 
 func (p *pgxCLI) printViaPager(str string) tea.Cmd {
 	if p.Printer.ShouldUsePager(str) {
+		if p.Printer.BuiltinPager() {
+			return ui.ShowViewerCmd(ui.TextViewerMsg(str))
+		}
 		cmd, ok := cliio.PagerCmd(str)
 		if !ok {
 			return ui.PrintCmd(str)
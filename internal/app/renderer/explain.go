@@ -0,0 +1,185 @@
+package renderer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/balaji01-4d/pgxcli/internal/config"
+	"github.com/fatih/color"
+)
+
+const (
+	// hotspotCritical and hotspotWarning are the shares of total execution
+	// time spent in a single node that get it highlighted.
+	hotspotCritical = 0.5
+	hotspotWarning  = 0.2
+	// misestimateFactor is how far actual rows may be from the estimate
+	// before the row counts are highlighted.
+	misestimateFactor = 10
+)
+
+var (
+	criticalColor    = color.New(color.FgHiRed, color.Bold)
+	warningColor     = color.New(color.FgHiYellow)
+	misestimateColor = color.New(color.FgHiMagenta)
+)
+
+type hotspotLevel int
+
+const (
+	hotspotNone hotspotLevel = iota
+	hotspotWarn
+	hotspotCrit
+)
+
+// hotspot classifies a node by its share of the plan's total time.
+func hotspot(n *PlanNode, total float64) hotspotLevel {
+	if total <= 0 || !n.Analyzed() {
+		return hotspotNone
+	}
+	switch share := n.SelfTime() / total; {
+	case share >= hotspotCritical:
+		return hotspotCrit
+	case share >= hotspotWarning:
+		return hotspotWarn
+	default:
+		return hotspotNone
+	}
+}
+
+// ExplainResult renders plan as an indented tree. Node names use the table
+// header color and details the column color; with EXPLAIN ANALYZE, nodes
+// taking a large share of the execution time and large row misestimates are
+// highlighted.
+func ExplainResult(plan *Plan, c *config.Config) string {
+	r := planRenderer{
+		total:  plan.ExecutionTime,
+		node:   color.New(getHeaderColor(c.Table.Color.Header)),
+		detail: color.New(getColumnColor(c.Table.Color.Column)),
+	}
+	if r.total == 0 {
+		r.total = plan.Root.TotalTime()
+	}
+
+	var sb strings.Builder
+	r.write(&sb, plan.Root, "", "")
+	if plan.PlanningTime > 0 {
+		fmt.Fprintf(&sb, "Planning Time: %.3f ms\n", plan.PlanningTime)
+	}
+	if plan.ExecutionTime > 0 {
+		fmt.Fprintf(&sb, "Execution Time: %.3f ms\n", plan.ExecutionTime)
+	}
+	return sb.String()
+}
+
+type planRenderer struct {
+	total  float64
+	node   *color.Color
+	detail *color.Color
+}
+
+// write renders n and its subtree. branch is drawn before the node's name
+// and indent before everything below it.
+func (r planRenderer) write(sb *strings.Builder, n *PlanNode, branch, indent string) {
+	label := r.node
+	switch hotspot(n, r.total) {
+	case hotspotCrit:
+		label = criticalColor
+	case hotspotWarn:
+		label = warningColor
+	}
+	fmt.Fprintf(sb, "%s%s  %s\n", branch, label.Sprint(n.Label()), r.stats(n))
+
+	bar := "   "
+	if len(n.Plans) > 0 {
+		bar = "│  "
+	}
+	for _, line := range nodeDetails(n) {
+		fmt.Fprintf(sb, "%s%s%s\n", indent, bar, r.detail.Sprint(line))
+	}
+
+	for i, child := range n.Plans {
+		if i == len(n.Plans)-1 {
+			r.write(sb, child, indent+"└─ ", indent+"   ")
+		} else {
+			r.write(sb, child, indent+"├─ ", indent+"│  ")
+		}
+	}
+}
+
+func (r planRenderer) stats(n *PlanNode) string {
+	parts := make([]string, 0, 4)
+	if !n.Analyzed() {
+		parts = append(parts, fmt.Sprintf("rows %s", formatRows(n.PlanRows)))
+	} else {
+		rows := fmt.Sprintf("rows %s → %s", formatRows(n.PlanRows), formatRows(n.ActualRows))
+		if factor, under := n.Misestimate(); factor >= misestimateFactor {
+			direction := "over"
+			if under {
+				direction = "under"
+			}
+			rows = misestimateColor.Sprintf("%s (%.0f× %sestimated)", rows, factor, direction)
+		}
+		parts = append(parts, rows)
+
+		timing := fmt.Sprintf("%.3f ms", n.TotalTime())
+		if r.total > 0 {
+			timing += fmt.Sprintf(" (%.0f%% self)", 100*n.SelfTime()/r.total)
+		}
+		parts = append(parts, timing, fmt.Sprintf("loops %s", formatRows(loops(n))))
+	}
+	parts = append(parts, fmt.Sprintf("cost %.2f..%.2f", n.StartupCost, n.TotalCost))
+	return strings.Join(parts, " · ")
+}
+
+// nodeDetails lists conditions, filters and buffer usage of a node.
+func nodeDetails(n *PlanNode) []string {
+	var lines []string
+	add := func(title, value string) {
+		if value != "" {
+			lines = append(lines, title+": "+value)
+		}
+	}
+
+	add("Index Cond", n.IndexCond)
+	add("Hash Cond", n.HashCond)
+	add("Merge Cond", n.MergeCond)
+	add("Join Filter", n.JoinFilter)
+	add("Filter", n.Filter)
+	if n.RowsRemovedByFilter > 0 {
+		add("Rows Removed by Filter", formatRows(n.RowsRemovedByFilter))
+	}
+	add("Buffers", formatBuffers(n))
+	return lines
+}
+
+func formatBuffers(n *PlanNode) string {
+	var parts []string
+	shared := blockCounts([]string{"hit", "read", "dirtied", "written"},
+		n.SharedHitBlocks, n.SharedReadBlocks, n.SharedDirtiedBlocks, n.SharedWrittenBlocks)
+	if shared != "" {
+		parts = append(parts, "shared "+shared)
+	}
+	if temp := blockCounts([]string{"read", "written"}, n.TempReadBlocks, n.TempWrittenBlocks); temp != "" {
+		parts = append(parts, "temp "+temp)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func blockCounts(names []string, counts ...int64) string {
+	var parts []string
+	for i, count := range counts {
+		if count > 0 {
+			parts = append(parts, fmt.Sprintf("%s=%d", names[i], count))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatRows prints row counts without a fraction when they are whole.
+func formatRows(rows float64) string {
+	if rows == float64(int64(rows)) {
+		return fmt.Sprintf("%d", int64(rows))
+	}
+	return fmt.Sprintf("%.2f", rows)
+}
@@ -0,0 +1,240 @@
+package renderer
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/balaji01-4d/pgxcli/internal/config"
+	"github.com/fatih/color"
+)
+
+var (
+	improvedColor  = color.New(color.FgHiGreen)
+	regressedColor = color.New(color.FgHiRed)
+)
+
+// Markers in front of each line of a plan diff.
+const (
+	diffSame    = "  "
+	diffChanged = "~ "
+	diffRemoved = "- "
+	diffAdded   = "+ "
+)
+
+// nodePair is an old and a new node matched by the diff. Either may be nil
+// when the node only exists in one of the plans.
+type nodePair struct {
+	old, new *PlanNode
+}
+
+// ExplainDiff renders a unified tree diff from old to new. Nodes are matched
+// by relation (or node type when they scan none), so a Seq Scan replaced by
+// an Index Scan on the same table shows as a change rather than a removal.
+// Changed node types, row estimates, total cost and actual time are
+// highlighted, decreases in green and increases in red.
+func ExplainDiff(old, new *Plan, c *config.Config) string {
+	d := planDiffer{
+		node:   color.New(getHeaderColor(c.Table.Color.Header)),
+		detail: color.New(getColumnColor(c.Table.Color.Column)),
+	}
+
+	var sb strings.Builder
+	d.write(&sb, nodePair{old.Root, new.Root}, "", "")
+
+	if line := deltaLine("Total Cost", old.Root.TotalCost, new.Root.TotalCost, ""); line != "" {
+		sb.WriteString(line)
+	}
+	if old.PlanningTime > 0 && new.PlanningTime > 0 {
+		sb.WriteString(deltaLine("Planning Time", old.PlanningTime, new.PlanningTime, " ms"))
+	}
+	if old.ExecutionTime > 0 && new.ExecutionTime > 0 {
+		sb.WriteString(deltaLine("Execution Time", old.ExecutionTime, new.ExecutionTime, " ms"))
+	}
+	return sb.String()
+}
+
+type planDiffer struct {
+	node   *color.Color
+	detail *color.Color
+}
+
+// write renders the pair and the alignment of its children.
+func (d planDiffer) write(sb *strings.Builder, pair nodePair, branch, indent string) {
+	var children []nodePair
+	switch {
+	case pair.old == nil:
+		fmt.Fprintf(sb, "%s%s%s\n", diffAdded, branch, improvedColor.Sprint(pair.new.Label()))
+		children = onlyNodes(pair.new.Plans, false)
+	case pair.new == nil:
+		fmt.Fprintf(sb, "%s%s%s\n", diffRemoved, branch, regressedColor.Sprint(pair.old.Label()))
+		children = onlyNodes(pair.old.Plans, true)
+	default:
+		d.writeMatched(sb, pair, branch)
+		children = alignNodes(pair.old.Plans, pair.new.Plans)
+	}
+
+	for i, child := range children {
+		if i == len(children)-1 {
+			d.write(sb, child, indent+"└─ ", indent+"   ")
+		} else {
+			d.write(sb, child, indent+"├─ ", indent+"│  ")
+		}
+	}
+}
+
+func (d planDiffer) writeMatched(sb *strings.Builder, pair nodePair, branch string) {
+	oldLabel, newLabel := pair.old.Label(), pair.new.Label()
+	stats, changed := nodeDelta(pair.old, pair.new)
+
+	marker, label := diffSame, d.node.Sprint(newLabel)
+	if oldLabel != newLabel {
+		marker = diffChanged
+		label = warningColor.Sprint(newLabel) + d.detail.Sprintf(" (was %s)", oldLabel)
+	} else if changed {
+		marker = diffChanged
+	}
+	fmt.Fprintf(sb, "%s%s%s  %s\n", marker, branch, label, stats)
+}
+
+// nodeDelta describes the estimates, cost and time of a matched node, and
+// reports whether any of them changed.
+func nodeDelta(old, new *PlanNode) (string, bool) {
+	parts := []string{
+		"rows " + compareValues(old.PlanRows, new.PlanRows, formatRows),
+	}
+	if old.Analyzed() && new.Analyzed() {
+		parts = append(parts, "actual "+compareValues(old.ActualRows, new.ActualRows, formatRows))
+	}
+	parts = append(parts, "cost "+compareValues(old.TotalCost, new.TotalCost, formatCost))
+	if old.Analyzed() && new.Analyzed() {
+		parts = append(parts, "time "+compareValues(old.TotalTime(), new.TotalTime(), formatMillis))
+	}
+
+	changed := old.PlanRows != new.PlanRows || old.TotalCost != new.TotalCost
+	return strings.Join(parts, " · "), changed
+}
+
+// compareValues prints a value, or "old → new (±N%)" when it changed.
+func compareValues(old, new float64, format func(float64) string) string {
+	if old == new {
+		return format(new)
+	}
+	return fmt.Sprintf("%s → %s %s", format(old), format(new), percentChange(old, new))
+}
+
+// percentChange formats the relative change from old to new, colored by
+// whether it went down (green) or up (red).
+func percentChange(old, new float64) string {
+	if old == 0 {
+		return regressedColor.Sprint("(new)")
+	}
+	change := 100 * (new - old) / old
+	text := fmt.Sprintf("(%+.0f%%)", change)
+	if math.Abs(change) < 0.5 {
+		return text
+	}
+	if change < 0 {
+		return improvedColor.Sprint(text)
+	}
+	return regressedColor.Sprint(text)
+}
+
+func deltaLine(title string, old, new float64, unit string) string {
+	if old == new {
+		return fmt.Sprintf("%s: %.3f%s\n", title, new, unit)
+	}
+	return fmt.Sprintf("%s: %.3f%s → %.3f%s %s\n", title, old, unit, new, unit, percentChange(old, new))
+}
+
+func formatCost(cost float64) string {
+	return fmt.Sprintf("%.2f", cost)
+}
+
+func formatMillis(ms float64) string {
+	return fmt.Sprintf("%.3f ms", ms)
+}
+
+// alignNodes matches the children of two nodes. Children with the same
+// match key are paired in order via their longest common subsequence; the
+// unmatched nodes between two matches are paired by position, and any left
+// over show as removed or added.
+func alignNodes(old, new []*PlanNode) []nodePair {
+	var pairs []nodePair
+	i, j := 0, 0
+	for _, m := range commonNodes(old, new) {
+		pairs = append(pairs, pairGap(old[i:m[0]], new[j:m[1]])...)
+		pairs = append(pairs, nodePair{old[m[0]], new[m[1]]})
+		i, j = m[0]+1, m[1]+1
+	}
+	return append(pairs, pairGap(old[i:], new[j:])...)
+}
+
+// commonNodes returns the index pairs of the longest common subsequence of
+// old and new by match key.
+func commonNodes(old, new []*PlanNode) [][2]int {
+	lengths := make([][]int, len(old)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(new)+1)
+	}
+	for i := len(old) - 1; i >= 0; i-- {
+		for j := len(new) - 1; j >= 0; j-- {
+			if matchKey(old[i]) == matchKey(new[j]) {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	var matches [][2]int
+	for i, j := 0, 0; i < len(old) && j < len(new); {
+		switch {
+		case matchKey(old[i]) == matchKey(new[j]):
+			matches = append(matches, [2]int{i, j})
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return matches
+}
+
+func pairGap(old, new []*PlanNode) []nodePair {
+	pairs := make([]nodePair, 0, max(len(old), len(new)))
+	for k := range max(len(old), len(new)) {
+		var pair nodePair
+		if k < len(old) {
+			pair.old = old[k]
+		}
+		if k < len(new) {
+			pair.new = new[k]
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs
+}
+
+func onlyNodes(nodes []*PlanNode, removed bool) []nodePair {
+	pairs := make([]nodePair, len(nodes))
+	for k, n := range nodes {
+		if removed {
+			pairs[k].old = n
+		} else {
+			pairs[k].new = n
+		}
+	}
+	return pairs
+}
+
+// matchKey identifies a node across plans: the relation it reads, or its
+// node type for nodes that read none.
+func matchKey(n *PlanNode) string {
+	if n.RelationName != "" {
+		return "relation:" + n.Schema + "." + n.RelationName + " " + n.Alias
+	}
+	return "node:" + n.NodeType
+}
@@ -0,0 +1,132 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/balaji01-4d/pgxcli/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const tunedPlanJSON = `[
+  {
+    "Plan": {
+      "Node Type": "Nested Loop",
+      "Join Type": "Left",
+      "Startup Cost": 0.57,
+      "Total Cost": 12.80,
+      "Plan Rows": 480,
+      "Actual Startup Time": 0.02,
+      "Actual Total Time": 2.0,
+      "Actual Rows": 500,
+      "Actual Loops": 1,
+      "Plans": [
+        {
+          "Node Type": "Index Scan",
+          "Relation Name": "orders",
+          "Alias": "o",
+          "Index Name": "orders_amount_idx",
+          "Startup Cost": 0.28,
+          "Total Cost": 8.50,
+          "Plan Rows": 480,
+          "Actual Startup Time": 0.01,
+          "Actual Total Time": 1.0,
+          "Actual Rows": 500,
+          "Actual Loops": 1
+        },
+        {
+          "Node Type": "Index Scan",
+          "Relation Name": "users",
+          "Alias": "u",
+          "Index Name": "users_pkey",
+          "Startup Cost": 0.29,
+          "Total Cost": 0.5,
+          "Plan Rows": 1,
+          "Actual Startup Time": 0.001,
+          "Actual Total Time": 0.001,
+          "Actual Rows": 1,
+          "Actual Loops": 500
+        }
+      ]
+    },
+    "Planning Time": 0.2,
+    "Execution Time": 2.5
+  }
+]`
+
+func TestExplainDiff(t *testing.T) {
+	old, err := ParsePlan([]byte(analyzedPlanJSON))
+	require.NoError(t, err)
+	tuned, err := ParsePlan([]byte(tunedPlanJSON))
+	require.NoError(t, err)
+
+	out := ExplainDiff(old, tuned, &config.Config{})
+	assertContainsFold(t, out,
+		"~ Nested Loop Left Join (was Hash Left Join)  rows 10 → 480 (+4700%) · actual 500 · cost 25.61 → 12.80 (-50%) · time 10.000 ms → 2.000 ms (-80%)",
+		"~ ├─ Index Scan using orders_amount_idx on orders o (was Seq Scan on orders o)  rows 500 → 480 (-4%)",
+		"  └─ Index Scan using users_pkey on users u  rows 1 · actual 1 · cost 0.50 · time 1.000 ms → 0.500 ms (-50%)",
+		"Total Cost: 25.610 → 12.800 (-50%)",
+		"Planning Time: 0.200 ms",
+		"Execution Time: 10.500 ms → 2.500 ms (-76%)",
+	)
+}
+
+func TestExplainDiff_AddedAndRemovedNodes(t *testing.T) {
+	old, err := ParsePlan([]byte(`[{"Plan": {"Node Type": "Seq Scan", "Relation Name": "t", "Alias": "t",
+		"Total Cost": 35.5, "Plan Rows": 2550}}]`))
+	require.NoError(t, err)
+	sorted, err := ParsePlan([]byte(`[{"Plan": {"Node Type": "Sort", "Total Cost": 180.0, "Plan Rows": 2550,
+		"Plans": [{"Node Type": "Seq Scan", "Relation Name": "t", "Alias": "t", "Total Cost": 35.5, "Plan Rows": 2550}]}}]`))
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		"~ Sort (was Seq Scan on t)  rows 2550 · cost 35.50 → 180.00 (+407%)\n"+
+			"+ └─ Seq Scan on t\n"+
+			"Total Cost: 35.500 → 180.000 (+407%)\n",
+		ExplainDiff(old, sorted, &config.Config{}))
+
+	assert.Equal(t,
+		"~ Seq Scan on t (was Sort)  rows 2550 · cost 180.00 → 35.50 (-80%)\n"+
+			"- └─ Seq Scan on t\n"+
+			"Total Cost: 180.000 → 35.500 (-80%)\n",
+		ExplainDiff(sorted, old, &config.Config{}))
+}
+
+func TestAlignNodes(t *testing.T) {
+	scan := func(rel string) *PlanNode { return &PlanNode{NodeType: "Seq Scan", RelationName: rel} }
+	a, b, c := scan("a"), scan("b"), scan("c")
+	hash := &PlanNode{NodeType: "Hash"}
+	memo := &PlanNode{NodeType: "Memoize"}
+
+	testCases := []struct {
+		name     string
+		old, new []*PlanNode
+		want     []nodePair
+	}{
+		{
+			name: "same relations",
+			old:  []*PlanNode{a, b},
+			new:  []*PlanNode{a, b},
+			want: []nodePair{{a, a}, {b, b}},
+		},
+		{
+			name: "unmatched nodes between matches pair by position",
+			old:  []*PlanNode{a, hash},
+			new:  []*PlanNode{a, memo},
+			want: []nodePair{{a, a}, {hash, memo}},
+		},
+		{
+			name: "extra nodes are added or removed",
+			old:  []*PlanNode{a, b},
+			new:  []*PlanNode{c, b, a},
+			want: []nodePair{{a, c}, {b, b}, {nil, a}},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, alignNodes(tc.old, tc.new))
+		})
+	}
+}
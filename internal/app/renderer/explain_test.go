@@ -0,0 +1,142 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/balaji01-4d/pgxcli/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const analyzedPlanJSON = `[
+  {
+    "Plan": {
+      "Node Type": "Hash Join",
+      "Join Type": "Left",
+      "Startup Cost": 1.09,
+      "Total Cost": 25.61,
+      "Plan Rows": 10,
+      "Plan Width": 40,
+      "Actual Startup Time": 0.05,
+      "Actual Total Time": 10.0,
+      "Actual Rows": 500,
+      "Actual Loops": 1,
+      "Hash Cond": "(o.user_id = u.id)",
+      "Shared Hit Blocks": 12,
+      "Shared Read Blocks": 3,
+      "Plans": [
+        {
+          "Node Type": "Seq Scan",
+          "Parent Relationship": "Outer",
+          "Relation Name": "orders",
+          "Alias": "o",
+          "Startup Cost": 0.00,
+          "Total Cost": 20.70,
+          "Plan Rows": 500,
+          "Plan Width": 24,
+          "Actual Startup Time": 0.01,
+          "Actual Total Time": 7.0,
+          "Actual Rows": 500,
+          "Actual Loops": 1,
+          "Filter": "(amount > 0)",
+          "Rows Removed by Filter": 20,
+          "Shared Hit Blocks": 10
+        },
+        {
+          "Node Type": "Index Scan",
+          "Parent Relationship": "Inner",
+          "Relation Name": "users",
+          "Alias": "u",
+          "Index Name": "users_pkey",
+          "Startup Cost": 0.29,
+          "Total Cost": 0.5,
+          "Plan Rows": 1,
+          "Plan Width": 16,
+          "Actual Startup Time": 0.001,
+          "Actual Total Time": 0.002,
+          "Actual Rows": 1,
+          "Actual Loops": 500,
+          "Index Cond": "(id = o.user_id)"
+        }
+      ]
+    },
+    "Planning Time": 0.2,
+    "Execution Time": 10.5
+  }
+]`
+
+func TestParsePlan(t *testing.T) {
+	plan, err := ParsePlan([]byte(analyzedPlanJSON))
+	require.NoError(t, err)
+
+	assert.True(t, plan.Analyzed())
+	assert.InDelta(t, 10.5, plan.ExecutionTime, 1e-9)
+	require.Len(t, plan.Root.Plans, 2)
+
+	join, scan, index := plan.Root, plan.Root.Plans[0], plan.Root.Plans[1]
+	assert.Equal(t, "Hash Left Join", join.Label())
+	assert.Equal(t, "Seq Scan on orders o", scan.Label())
+	assert.Equal(t, "Index Scan using users_pkey on users u", index.Label())
+
+	assert.InDelta(t, 1.0, index.TotalTime(), 1e-9)
+	assert.InDelta(t, 2.0, join.SelfTime(), 1e-9)
+	assert.InDelta(t, 7.0, scan.SelfTime(), 1e-9)
+
+	factor, under := join.Misestimate()
+	assert.InDelta(t, 50.0, factor, 1e-9)
+	assert.True(t, under)
+
+	var visited []string
+	join.Walk(func(n *PlanNode, depth int) {
+		visited = append(visited, n.NodeType)
+		assert.Equal(t, n != join, depth == 1)
+	})
+	assert.Equal(t, []string{"Hash Join", "Seq Scan", "Index Scan"}, visited)
+}
+
+func TestParsePlan_Errors(t *testing.T) {
+	_, err := ParsePlan([]byte("not json"))
+	assert.ErrorContains(t, err, "parsing plan")
+
+	_, err = ParsePlan([]byte("[]"))
+	assert.EqualError(t, err, "parsing plan: no plan found")
+}
+
+func TestHotspot(t *testing.T) {
+	plan, err := ParsePlan([]byte(analyzedPlanJSON))
+	require.NoError(t, err)
+
+	total := plan.ExecutionTime
+	assert.Equal(t, hotspotCrit, hotspot(plan.Root.Plans[0], total))
+	assert.Equal(t, hotspotNone, hotspot(plan.Root.Plans[1], total))
+	assert.Equal(t, hotspotWarn, hotspot(plan.Root, 9))
+	assert.Equal(t, hotspotNone, hotspot(plan.Root, 0))
+}
+
+func TestExplainResult(t *testing.T) {
+	plan, err := ParsePlan([]byte(analyzedPlanJSON))
+	require.NoError(t, err)
+
+	out := ExplainResult(plan, &config.Config{})
+	assertContainsFold(t, out,
+		"Hash Left Join  rows 10 → 500 (50× underestimated) · 10.000 ms (19% self) · loops 1 · cost 1.09..25.61",
+		"│  Hash Cond: (o.user_id = u.id)",
+		"│  Buffers: shared hit=12 read=3",
+		"├─ Seq Scan on orders o  rows 500 → 500 · 7.000 ms (67% self)",
+		"│     Filter: (amount > 0)",
+		"│     Rows Removed by Filter: 20",
+		"└─ Index Scan using users_pkey on users u  rows 1 → 1 · 1.000 ms (10% self) · loops 500",
+		"      Index Cond: (id = o.user_id)",
+		"Planning Time: 0.200 ms",
+		"Execution Time: 10.500 ms",
+	)
+}
+
+func TestExplainResult_EstimatesOnly(t *testing.T) {
+	plan, err := ParsePlan([]byte(`[{"Plan": {"Node Type": "Seq Scan", "Relation Name": "t", "Alias": "t",
+		"Startup Cost": 0, "Total Cost": 35.5, "Plan Rows": 2550, "Plan Width": 4}}]`))
+	require.NoError(t, err)
+
+	assert.False(t, plan.Analyzed())
+	assert.Equal(t, "Seq Scan on t  rows 2550 · cost 0.00..35.50\n", ExplainResult(plan, &config.Config{}))
+}
@@ -0,0 +1,37 @@
+package renderer
+
+import (
+	"fmt"
+
+	"github.com/balaji01-4d/pgxcli/internal/config"
+	"github.com/balaji01-4d/pgxcli/internal/history"
+)
+
+var historyColumns = []string{"time", "host", "database", "user", "duration", "rows", "error", "query"}
+
+// HistoryResult renders history entries as a table, oldest first. Entries
+// migrated from older history files only have a query, so their other
+// columns are left empty.
+func HistoryResult(entries []history.Entry, c *config.Config) (string, error) {
+	rows := make([][]any, len(entries))
+	for i, e := range entries {
+		rows[i] = historyRow(e)
+	}
+	return renderData(staticData{columns: historyColumns, rows: rows}, c)
+}
+
+func historyRow(e history.Entry) []any {
+	if e.Time.IsZero() {
+		return []any{nil, nil, nil, nil, nil, nil, nil, e.Query}
+	}
+	return []any{
+		e.Time.Local().Format("2006-01-02 15:04:05"),
+		e.Host,
+		e.Database,
+		e.User,
+		fmt.Sprintf("%.3fs", e.Duration.Seconds()),
+		e.Rows,
+		e.Error,
+		e.Query,
+	}
+}
@@ -0,0 +1,44 @@
+package renderer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/balaji01-4d/pgxcli/internal/config"
+	"github.com/balaji01-4d/pgxcli/internal/history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryResult(t *testing.T) {
+	t.Parallel()
+
+	ran := time.Date(2026, 10, 1, 12, 0, 0, 0, time.Local)
+	got, err := HistoryResult([]history.Entry{
+		{Query: "select 1"},
+		{
+			Query:    "delete from users",
+			Time:     ran,
+			Host:     "db.internal",
+			Database: "app",
+			User:     "alice",
+			Duration: 1500 * time.Millisecond,
+			Rows:     42,
+		},
+		{Query: "drop table users", Time: ran, Error: "permission denied"},
+	}, &config.Config{})
+	require.NoError(t, err)
+
+	assertContainsFold(t, got,
+		"time", "duration", "rows", "error", "query",
+		"select 1",
+		"2026-10-01 12:00:00", "db.internal", "app", "alice", "1.500s", "42", "delete from users",
+		"permission denied", "drop table users",
+	)
+}
+
+func TestHistoryRow_Migrated(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []any{nil, nil, nil, nil, nil, nil, nil, "select 1"}, historyRow(history.Entry{Query: "select 1"}))
+}
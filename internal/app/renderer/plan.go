@@ -0,0 +1,154 @@
+package renderer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Plan is a parsed EXPLAIN (FORMAT JSON) result.
+type Plan struct {
+	Root          *PlanNode `json:"Plan"`
+	PlanningTime  float64   `json:"Planning Time"`
+	ExecutionTime float64   `json:"Execution Time"`
+}
+
+// PlanNode is one node of an EXPLAIN plan. Actual* fields are only set for
+// EXPLAIN ANALYZE, and buffer counts only with the BUFFERS option.
+type PlanNode struct {
+	NodeType           string `json:"Node Type"`
+	ParentRelationship string `json:"Parent Relationship"`
+	RelationName       string `json:"Relation Name"`
+	Schema             string `json:"Schema"`
+	Alias              string `json:"Alias"`
+	IndexName          string `json:"Index Name"`
+	JoinType           string `json:"Join Type"`
+	Strategy           string `json:"Strategy"`
+
+	StartupCost float64 `json:"Startup Cost"`
+	TotalCost   float64 `json:"Total Cost"`
+	PlanRows    float64 `json:"Plan Rows"`
+	PlanWidth   int     `json:"Plan Width"`
+
+	ActualStartupTime *float64 `json:"Actual Startup Time"`
+	ActualTotalTime   *float64 `json:"Actual Total Time"`
+	ActualRows        float64  `json:"Actual Rows"`
+	ActualLoops       float64  `json:"Actual Loops"`
+
+	SharedHitBlocks     int64 `json:"Shared Hit Blocks"`
+	SharedReadBlocks    int64 `json:"Shared Read Blocks"`
+	SharedDirtiedBlocks int64 `json:"Shared Dirtied Blocks"`
+	SharedWrittenBlocks int64 `json:"Shared Written Blocks"`
+	TempReadBlocks      int64 `json:"Temp Read Blocks"`
+	TempWrittenBlocks   int64 `json:"Temp Written Blocks"`
+
+	Filter              string  `json:"Filter"`
+	IndexCond           string  `json:"Index Cond"`
+	HashCond            string  `json:"Hash Cond"`
+	MergeCond           string  `json:"Merge Cond"`
+	JoinFilter          string  `json:"Join Filter"`
+	RowsRemovedByFilter float64 `json:"Rows Removed by Filter"`
+
+	Plans []*PlanNode `json:"Plans"`
+}
+
+// ParsePlan parses the output of EXPLAIN (FORMAT JSON), which is a
+// one-element array holding the plan and its timings.
+func ParsePlan(data []byte) (*Plan, error) {
+	var plans []Plan
+	if err := json.Unmarshal(data, &plans); err != nil {
+		return nil, fmt.Errorf("parsing plan: %w", err)
+	}
+	if len(plans) == 0 || plans[0].Root == nil {
+		return nil, errors.New("parsing plan: no plan found")
+	}
+	return &plans[0], nil
+}
+
+// Analyzed reports whether the plan was produced by EXPLAIN ANALYZE.
+func (p *Plan) Analyzed() bool {
+	return p.Root.Analyzed()
+}
+
+// Analyzed reports whether the node carries actual run-time statistics.
+func (n *PlanNode) Analyzed() bool {
+	return n.ActualTotalTime != nil
+}
+
+var aggregateLabels = map[string]string{
+	"Hashed": "HashAggregate",
+	"Sorted": "GroupAggregate",
+	"Mixed":  "MixedAggregate",
+}
+
+// Label describes the node the way EXPLAIN's text format does, e.g.
+// "Index Scan using users_pkey on users u".
+func (n *PlanNode) Label() string {
+	label := n.NodeType
+	switch {
+	case n.JoinType != "" && n.JoinType != "Inner":
+		label = strings.TrimSuffix(n.NodeType, " Join") + " " + n.JoinType + " Join"
+	case n.NodeType == "Aggregate" && aggregateLabels[n.Strategy] != "":
+		label = aggregateLabels[n.Strategy]
+	}
+
+	if n.IndexName != "" {
+		label += " using " + n.IndexName
+	}
+	if n.RelationName != "" {
+		label += " on " + n.RelationName
+		if n.Alias != "" && n.Alias != n.RelationName {
+			label += " " + n.Alias
+		}
+	}
+	return label
+}
+
+// TotalTime returns the node's actual time across all loops, in milliseconds.
+func (n *PlanNode) TotalTime() float64 {
+	if n.ActualTotalTime == nil {
+		return 0
+	}
+	return *n.ActualTotalTime * loops(n)
+}
+
+// SelfTime returns the time spent in the node itself, excluding its
+// children, in milliseconds.
+func (n *PlanNode) SelfTime() float64 {
+	self := n.TotalTime()
+	for _, child := range n.Plans {
+		self -= child.TotalTime()
+	}
+	return max(self, 0)
+}
+
+// Misestimate returns how far the actual rows per loop were from the
+// planner's estimate, as a factor of at least 1, and whether the planner
+// underestimated.
+func (n *PlanNode) Misestimate() (float64, bool) {
+	if !n.Analyzed() {
+		return 1, false
+	}
+	est, actual := max(n.PlanRows, 1), max(n.ActualRows, 1)
+	if actual > est {
+		return actual / est, true
+	}
+	return est / actual, false
+}
+
+func loops(n *PlanNode) float64 {
+	return max(n.ActualLoops, 1)
+}
+
+// Walk calls fn for n and each of its descendants, depth first.
+func (n *PlanNode) Walk(fn func(node *PlanNode, depth int)) {
+	n.walk(fn, 0)
+}
+
+func (n *PlanNode) walk(fn func(node *PlanNode, depth int), depth int) {
+	fn(n, depth)
+	for _, child := range n.Plans {
+		child.walk(fn, depth+1)
+	}
+}
@@ -1,6 +1,7 @@
 package renderer
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/balaji01-4d/pgxcli/internal/config"
@@ -38,3 +39,18 @@ func Table(data Data, w io.Writer, c *config.Config) error {
 	}
 	return t.Render()
 }
+
+// CellText formats a value the way Table prints it: NULL as an empty string
+// and byte slices as text.
+func CellText(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(val)
+	case string:
+		return val
+	default:
+		return fmt.Sprint(val)
+	}
+}
@@ -0,0 +1,117 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/balaji01-4d/pgxcli/internal/app/ui"
+	"github.com/balaji01-4d/pgxcli/internal/config"
+	"github.com/balaji01-4d/pgxcli/internal/database"
+)
+
+// configChanged applies the live settings of the config file once it was
+// saved, or reports why the saved file is ignored.
+func (p *pgxCLI) configChanged(client *database.Client, cfg *config.Config, err error) {
+	if err != nil {
+		p.logger.Warn("ignoring config file change", "error", err)
+		p.program.Send(ui.ExecCmdMsg{Cmd: p.printError(fmt.Errorf("config file not reloaded: %w", err))})
+		return
+	}
+	p.program.Send(ui.SettingsMsg{Apply: func() ui.Settings {
+		return p.reload(client, cfg)
+	}})
+}
+
+// reload takes the live settings of cfg, except those changed with \config.
+// The prompt of the connection profile keeps replacing the prompt of [main],
+// as it does at startup.
+func (p *pgxCLI) reload(client *database.Client, cfg *config.Config) ui.Settings {
+	if connection, ok := cfg.Profile(p.profile); ok && connection.Prompt != "" {
+		cfg.Main.Prompt = connection.Prompt
+	}
+
+	var changed []string
+	for _, key := range config.LiveKeys() {
+		if p.overrides[key] {
+			continue
+		}
+		value, _ := cfg.Get(key)
+		current, _ := p.config.Get(key)
+		if value == current {
+			continue
+		}
+		if err := p.setSetting(key, value); err != nil {
+			p.logger.Error("failed to apply reloaded setting", "key", key, "error", err)
+			continue
+		}
+		changed = append(changed, key)
+	}
+	if len(changed) == 0 {
+		return ui.Settings{}
+	}
+
+	p.logger.Info("config file reloaded", "changed", changed)
+	return p.settings(client, "Config reloaded, changed: "+strings.Join(changed, ", "))
+}
+
+// configCommand shows the live settings for \config, or changes one for the
+// rest of the session without touching the config file.
+func (p *pgxCLI) configCommand(client *database.Client, action database.ConfigAction) (string, bool, error) {
+	key := strings.ToLower(action.Key)
+	if action.Set {
+		if err := p.setSetting(key, action.Value); err != nil {
+			return "", false, err
+		}
+		p.overrides[key] = true
+		p.program.Send(ui.SettingsMsg{Apply: func() ui.Settings {
+			return p.settings(client, "")
+		}})
+		return p.settingLine(key) + "\n", false, nil
+	}
+
+	keys := config.LiveKeys()
+	if key != "" {
+		if _, err := p.config.Get(key); err != nil {
+			return "", false, err
+		}
+		keys = []string{key}
+	}
+	lines := make([]string, len(keys))
+	for i, k := range keys {
+		lines[i] = p.settingLine(k)
+	}
+	return strings.Join(lines, "\n") + "\n", false, nil
+}
+
+// setSetting changes a live setting, and the pager mode along with it.
+func (p *pgxCLI) setSetting(key, value string) error {
+	if err := p.config.Set(key, value); err != nil {
+		return err
+	}
+	if key == "pager" {
+		return p.Printer.SetPagerMode(p.config.Main.Pager)
+	}
+	return nil
+}
+
+// settingLine renders a live setting as it is written in the config file,
+// marking those changed for this session.
+func (p *pgxCLI) settingLine(key string) string {
+	value, _ := p.config.Get(key)
+	line := fmt.Sprintf("%s = %s", key, strconv.Quote(value))
+	if p.overrides[key] {
+		line += "  (this session)"
+	}
+	return line
+}
+
+// settings describes the prompt and highlighting style of the current
+// settings for the ui.
+func (p *pgxCLI) settings(client *database.Client, notice string) ui.Settings {
+	return ui.Settings{
+		Prefix: client.ParsePrompt(p.config.Main.Prompt),
+		Style:  string(p.config.Main.Style),
+		Notice: notice,
+	}
+}
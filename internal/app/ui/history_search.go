@@ -0,0 +1,252 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/balaji01-4d/pgxcli/internal/history"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	// historySearchRows is the most entries listed at once.
+	historySearchRows = 8
+	// historyPreviewLines is the most lines of the selected entry previewed.
+	historyPreviewLines = 10
+)
+
+var (
+	historySelectedStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#E0DEF4"))
+	historyMatchStyle    = lipgloss.NewStyle().Underline(true).Foreground(lipgloss.Color("#F6C177"))
+	historyInfoStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#6E6A86"))
+)
+
+type historyMatch struct {
+	history.Entry
+	// indexes are the rune positions of the query matched by the filter.
+	indexes []int
+	score   int
+}
+
+// historySearch is the state of the ctrl+r overlay.
+type historySearch struct {
+	// entries are unique queries, most recent first.
+	entries []history.Entry
+	query   []rune
+	matches []historyMatch
+	cursor  int
+}
+
+func newHistorySearch(entries []history.Entry) *historySearch {
+	s := &historySearch{}
+	seen := make(map[string]bool, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if strings.TrimSpace(entry.Query) == "" || seen[entry.Query] {
+			continue
+		}
+		seen[entry.Query] = true
+		s.entries = append(s.entries, entry)
+	}
+	s.filter()
+	return s
+}
+
+// filter lists the entries fuzzy-matching the query, best match first and
+// most recent first among equal matches.
+func (s *historySearch) filter() {
+	s.matches = s.matches[:0]
+	for _, entry := range s.entries {
+		score, indexes, ok := fuzzyMatch(string(s.query), entry.Query)
+		if ok {
+			s.matches = append(s.matches, historyMatch{Entry: entry, indexes: indexes, score: score})
+		}
+	}
+	slices.SortStableFunc(s.matches, func(a, b historyMatch) int {
+		return b.score - a.score
+	})
+	s.cursor = 0
+}
+
+// fuzzyMatch reports whether the runes of pattern appear in order in text,
+// ignoring case, and scores the match: consecutive runes and runes starting
+// a word score higher, so "selusr" ranks "SELECT * FROM users" above a
+// scattered match.
+func fuzzyMatch(pattern, text string) (int, []int, bool) {
+	if pattern == "" {
+		return 0, nil, true
+	}
+
+	patternRunes := []rune(strings.ToLower(pattern))
+	textRunes := []rune(text)
+	indexes := make([]int, 0, len(patternRunes))
+	score, p := 0, 0
+	for i, r := range textRunes {
+		if p == len(patternRunes) {
+			break
+		}
+		if unicode.ToLower(r) != patternRunes[p] {
+			continue
+		}
+		score++
+		if len(indexes) > 0 && indexes[len(indexes)-1] == i-1 {
+			score += 5
+		}
+		if i == 0 || !isWordRune(textRunes[i-1]) {
+			score += 3
+		}
+		indexes = append(indexes, i)
+		p++
+	}
+	if p < len(patternRunes) {
+		return 0, nil, false
+	}
+	return score, indexes, true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// selected returns the highlighted entry, if any entry matches.
+func (s *historySearch) selected() (historyMatch, bool) {
+	if len(s.matches) == 0 {
+		return historyMatch{}, false
+	}
+	return s.matches[s.cursor], true
+}
+
+func (s *historySearch) move(delta int) {
+	if len(s.matches) > 0 {
+		s.cursor = max(min(s.cursor+delta, len(s.matches)-1), 0)
+	}
+}
+
+// openHistorySearch shows the ctrl+r overlay over the input.
+func (m *Model) openHistorySearch() {
+	m.search = newHistorySearch(m.scopedHistory())
+}
+
+// answerHistorySearch handles a key in the history search overlay. Enter
+// puts the selected query into the input; esc leaves the input unchanged.
+func (m *Model) answerHistorySearch(msg tea.KeyMsg) tea.Cmd {
+	s := m.search
+	switch msg.String() {
+	case "enter":
+		if match, ok := s.selected(); ok {
+			m.input.SetValue(match.Query)
+		}
+		m.search = nil
+	case "esc", "ctrl+c", "ctrl+g":
+		m.search = nil
+	case "up", "ctrl+p", "ctrl+r":
+		s.move(1)
+	case "down", "ctrl+n", "ctrl+s":
+		s.move(-1)
+	case "backspace":
+		if len(s.query) > 0 {
+			s.query = s.query[:len(s.query)-1]
+			s.filter()
+		}
+	default:
+		if press, ok := msg.(tea.KeyPressMsg); ok && press.Key().Text != "" {
+			s.query = append(s.query, []rune(press.Key().Text)...)
+			s.filter()
+		}
+	}
+	return nil
+}
+
+// historySearchView lists the matching entries, oldest at the top so the
+// most recent sits next to the search line, followed by a highlighted
+// preview of the selected entry.
+func (m *Model) historySearchView() string {
+	s := m.search
+	now := time.Now()
+
+	first := max(s.cursor-historySearchRows+1, 0)
+	last := min(first+historySearchRows, len(s.matches))
+	lines := make([]string, 0, historySearchRows+historyPreviewLines+2)
+	for i := last - 1; i >= first; i-- {
+		lines = append(lines, m.historySearchLine(s.matches[i], i == s.cursor, now))
+	}
+
+	if match, ok := s.selected(); ok {
+		lines = append(lines, historyInfoStyle.Render(strings.Repeat("─", max(m.width, 1))))
+		preview := strings.Split(postgresHighlighter(m.style)(match.Query), "\n")
+		if len(preview) > historyPreviewLines {
+			preview = append(preview[:historyPreviewLines], historyInfoStyle.Render("…"))
+		}
+		lines = append(lines, preview...)
+	}
+
+	search := fmt.Sprintf("history search (%d/%d): %s", len(s.matches), len(s.entries), string(s.query))
+	lines = append(lines, userInputStyle.Render(search))
+	return strings.Join(lines, "\n")
+}
+
+// historySearchLine renders one entry on a line: its first line with the
+// matched runes underlined, then when and where it ran.
+func (m *Model) historySearchLine(match historyMatch, selected bool, now time.Time) string {
+	info := match.describe(now)
+	width := max(m.width-ansi.StringWidth(info)-4, 10)
+
+	marker, style := "  ", appOutputStyle
+	if selected {
+		marker, style = "> ", historySelectedStyle
+	}
+
+	var sb strings.Builder
+	matched := make(map[int]bool, len(match.indexes))
+	for _, i := range match.indexes {
+		matched[i] = true
+	}
+	for i, r := range []rune(sanitizeCell(match.Query)) {
+		if matched[i] {
+			sb.WriteString(historyMatchStyle.Render(string(r)))
+		} else {
+			sb.WriteString(style.Render(string(r)))
+		}
+	}
+	query := ansi.Truncate(sb.String(), width, "…")
+	padding := strings.Repeat(" ", max(width-ansi.StringWidth(query), 0))
+	return marker + query + padding + "  " + historyInfoStyle.Render(info)
+}
+
+// describe says when and against which database the query ran, when known,
+// and whether it failed.
+func (e historyMatch) describe(now time.Time) string {
+	parts := make([]string, 0, 3)
+	if !e.Time.IsZero() {
+		parts = append(parts, timeAgo(e.Time, now))
+	}
+	if e.Database != "" {
+		parts = append(parts, e.Database)
+	}
+	if e.Failed() {
+		parts = append(parts, "failed")
+	}
+	return strings.Join(parts, " · ")
+}
+
+// timeAgo formats t relative to now, falling back to the date after a week.
+func timeAgo(t, now time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	case d < 7*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	default:
+		return t.Format("2006-01-02")
+	}
+}
@@ -0,0 +1,137 @@
+package ui
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Balaji01-4D/bubbline/editline"
+	"github.com/balaji01-4d/pgxcli/internal/history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	score, indexes, ok := fuzzyMatch("selusr", "SELECT * FROM users")
+	require.True(t, ok)
+	assert.Equal(t, []int{0, 1, 2, 14, 15, 17}, indexes)
+
+	scattered, _, ok := fuzzyMatch("selusr", "select last_value from user_sequences_r")
+	require.True(t, ok)
+	assert.Greater(t, score, scattered)
+
+	_, _, ok = fuzzyMatch("xyz", "select 1")
+	assert.False(t, ok)
+
+	score, indexes, ok = fuzzyMatch("", "select 1")
+	assert.True(t, ok)
+	assert.Zero(t, score)
+	assert.Nil(t, indexes)
+}
+
+func TestHistorySearch(t *testing.T) {
+	ran := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	s := newHistorySearch([]history.Entry{
+		{Query: "select 1"},
+		{Query: "select * from users"},
+		{Query: "  "},
+		{Query: "select 1", Time: ran, Database: "app"},
+		{Query: "update users set x = 1"},
+	})
+
+	require.Len(t, s.entries, 3, "blank and repeated queries are listed once")
+	assert.Equal(t, []string{"update users set x = 1", "select 1", "select * from users"}, matchQueries(s))
+	assert.Equal(t, history.Entry{Query: "select 1", Time: ran, Database: "app"}, s.entries[1], "the most recent run is kept")
+
+	s.query = []rune("users")
+	s.filter()
+	assert.Equal(t, []string{"select * from users", "update users set x = 1"}, matchQueries(s),
+		"whole words rank first, then the most recent")
+
+	s.move(5)
+	match, ok := s.selected()
+	require.True(t, ok)
+	assert.Equal(t, "update users set x = 1", match.Query)
+
+	s.query = []rune("nothing")
+	s.filter()
+	_, ok = s.selected()
+	assert.False(t, ok)
+}
+
+func matchQueries(s *historySearch) []string {
+	queries := make([]string, len(s.matches))
+	for i, m := range s.matches {
+		queries[i] = m.Query
+	}
+	return queries
+}
+
+func TestHistoryMatchDescribe(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		entry historyMatch
+		want  string
+	}{
+		{historyMatch{}, ""},
+		{historyMatch{Entry: history.Entry{Time: now.Add(-10 * time.Second)}}, "just now"},
+		{historyMatch{Entry: history.Entry{Time: now.Add(-5 * time.Minute), Database: "app"}}, "5m ago · app"},
+		{historyMatch{Entry: history.Entry{Time: now.Add(-3 * time.Hour)}}, "3h ago"},
+		{historyMatch{Entry: history.Entry{Time: now.Add(-50 * time.Hour)}}, "2d ago"},
+		{historyMatch{Entry: history.Entry{Time: now.AddDate(0, -1, 0)}}, "2026-09-19"},
+		{historyMatch{Entry: history.Entry{Database: "app"}}, "app"},
+		{historyMatch{Entry: history.Entry{Database: "app", Error: "syntax error"}}, "app · failed"},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.want, tc.entry.describe(now))
+	}
+}
+
+func TestRecordHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	ran := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	m := &Model{historyFile: path}
+
+	require.NoError(t, m.recordHistory(Outcome{Duration: time.Second}), "nothing is pending")
+	assert.Empty(t, m.history)
+
+	m.pending = &history.Entry{Query: "select * from missing", Time: ran, Database: "app"}
+	require.NoError(t, m.recordHistory(Outcome{Duration: time.Second, Rows: 3, Err: errors.New("relation does not exist")}))
+	assert.Nil(t, m.pending)
+
+	want := []history.Entry{{
+		Query:    "select * from missing",
+		Time:     ran,
+		Database: "app",
+		Duration: time.Second,
+		Rows:     3,
+		Error:    "relation does not exist",
+	}}
+	assert.Equal(t, want, m.history)
+
+	saved, err := history.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, want, saved)
+}
+
+func TestAddHistory(t *testing.T) {
+	m := &Model{input: editline.New(0, 0), session: Session{Host: "db", Database: "app", User: "alice"}}
+	m.SetHistoryOptions(HistoryOptions{
+		IgnoreSpace: true,
+		Redact: func(s string) string {
+			return strings.ReplaceAll(s, "hunter2", "********")
+		},
+	})
+
+	m.addHistory(" select secret_stuff()")
+	assert.Nil(t, m.pending, "inputs starting with a space are not kept")
+
+	m.addHistory("ALTER ROLE app PASSWORD 'hunter2'")
+	require.NotNil(t, m.pending)
+	assert.Equal(t, "ALTER ROLE app PASSWORD '********'", m.pending.Query)
+	assert.Equal(t, "db", m.pending.Host)
+	assert.Equal(t, "app", m.pending.Database)
+	assert.Equal(t, "alice", m.pending.User)
+}
@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"github.com/Balaji01-4D/bubbline/editline"
+	"github.com/balaji01-4d/pgxcli/internal/config"
+)
+
+// keyBindings returns the editline bindings that [keys] can rebind, by the
+// action names listed in config.KeyActions.
+func keyBindings(km *editline.KeyMap) map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"character_backward":        &km.CharacterBackward,
+		"character_forward":         &km.CharacterForward,
+		"word_backward":             &km.WordBackward,
+		"word_forward":              &km.WordForward,
+		"line_start":                &km.LineStart,
+		"line_end":                  &km.LineEnd,
+		"line_previous":             &km.LinePrevious,
+		"line_next":                 &km.LineNext,
+		"input_begin":               &km.InputBegin,
+		"input_end":                 &km.InputEnd,
+		"delete_character_backward": &km.DeleteCharacterBackward,
+		"delete_character_forward":  &km.DeleteCharacterForward,
+		"delete_word_backward":      &km.DeleteWordBackward,
+		"delete_word_forward":       &km.DeleteWordForward,
+		"delete_before_cursor":      &km.DeleteBeforeCursor,
+		"delete_after_cursor":       &km.DeleteAfterCursor,
+		"insert_newline":            &km.InsertNewline,
+		"paste":                     &km.Paste,
+		"end_of_input":              &km.EndOfInput,
+		"history_previous":          &km.HistoryPrevious,
+		"history_next":              &km.HistoryNext,
+		"reverse_search":            &km.ReverseSearch,
+		"external_edit":             &km.ExternalEdit,
+	}
+}
+
+// applyKeyBindings replaces the keys of each action configured in [keys].
+// The config is validated on load, so unknown actions cannot occur.
+func applyKeyBindings(km *editline.KeyMap, keys config.KeysConfig) {
+	bindings := keyBindings(km)
+	for action, keys := range keys {
+		if binding, ok := bindings[action]; ok {
+			binding.SetKeys(keys...)
+		}
+	}
+}
+
+var keyCodes = map[string]rune{
+	"up":        tea.KeyUp,
+	"down":      tea.KeyDown,
+	"left":      tea.KeyLeft,
+	"right":     tea.KeyRight,
+	"home":      tea.KeyHome,
+	"end":       tea.KeyEnd,
+	"pgup":      tea.KeyPgUp,
+	"pgdown":    tea.KeyPgDown,
+	"insert":    tea.KeyInsert,
+	"delete":    tea.KeyDelete,
+	"backspace": tea.KeyBackspace,
+	"enter":     tea.KeyEnter,
+	"tab":       tea.KeyTab,
+	"esc":       tea.KeyEscape,
+	"space":     tea.KeySpace,
+}
+
+var keyMods = map[string]tea.KeyMod{
+	"ctrl":  tea.ModCtrl,
+	"alt":   tea.ModAlt,
+	"shift": tea.ModShift,
+	"meta":  tea.ModMeta,
+	"super": tea.ModSuper,
+	"hyper": tea.ModHyper,
+}
+
+// keyPress builds the key press that a binding key such as "ctrl+a" or
+// "up" matches, so actions can be triggered by their current binding.
+func keyPress(k string) tea.KeyPressMsg {
+	var msg tea.KeyPressMsg
+	name := k
+	// the last character is never a separator, so "ctrl++" is ctrl and "+"
+	if i := strings.LastIndex(k[:max(len(k)-1, 0)], "+"); i > 0 {
+		for _, mod := range strings.Split(k[:i], "+") {
+			msg.Mod |= keyMods[mod]
+		}
+		name = k[i+1:]
+	}
+
+	if code, ok := keyCodes[name]; ok {
+		msg.Code = code
+		return msg
+	}
+	if code := functionKey(name); code != 0 {
+		msg.Code = code
+		return msg
+	}
+
+	r, _ := utf8.DecodeRuneInString(name)
+	msg.Code = r
+	if msg.Mod&^tea.ModShift == 0 {
+		msg.Text = name
+	}
+	return msg
+}
+
+func functionKey(name string) rune {
+	n, err := strconv.Atoi(strings.TrimPrefix(name, "f"))
+	if !strings.HasPrefix(name, "f") || err != nil || n < 1 || n > 20 {
+		return 0
+	}
+	return tea.KeyF1 + rune(n-1)
+}
@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/Balaji01-4D/bubbline/editline"
+	"github.com/balaji01-4d/pgxcli/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyBindings_CoverConfigActions(t *testing.T) {
+	bindings := keyBindings(&editline.KeyMap{})
+	assert.Len(t, bindings, len(config.KeyActions))
+	for _, action := range config.KeyActions {
+		assert.Contains(t, bindings, action)
+	}
+}
+
+func TestApplyKeyBindings(t *testing.T) {
+	var km editline.KeyMap
+	applyKeyBindings(&km, config.KeysConfig{"external_edit": {"ctrl+x", "f2"}})
+	assert.Equal(t, []string{"ctrl+x", "f2"}, km.ExternalEdit.Keys())
+}
+
+func TestKeyPress(t *testing.T) {
+	for _, k := range []string{"a", "G", "$", "ctrl+a", "alt+b", "ctrl+alt+d", "up", "ctrl+right", "esc", "f12", "enter", "ctrl++"} {
+		assert.Equal(t, k, keyPress(k).String(), k)
+	}
+}
+
+func TestViMode(t *testing.T) {
+	m := &Model{input: editline.New(0, 0), vi: &viState{}}
+
+	press := func(k string) bool {
+		_, handled := m.handleViKey(keyPress(k))
+		return handled
+	}
+
+	assert.Equal(t, "INSERT", m.vi.label())
+	assert.False(t, press("x"), "insert mode types text")
+	assert.True(t, press("esc"))
+	assert.Equal(t, "NORMAL", m.vi.label())
+
+	assert.True(t, press("d"))
+	assert.Equal(t, "d", m.vi.pending)
+	assert.True(t, press("i"))
+	assert.Equal(t, "di", m.vi.pending)
+	assert.True(t, press("w"))
+	assert.Empty(t, m.vi.pending, "diw ran")
+	assert.Equal(t, viNormal, m.vi.mode)
+
+	assert.True(t, press("z"), "unknown commands are swallowed")
+	assert.Empty(t, m.vi.pending)
+	assert.False(t, press("enter"), "enter still submits")
+	assert.False(t, press("ctrl+c"))
+
+	assert.True(t, press("c"))
+	assert.True(t, press("w"))
+	assert.Equal(t, viInsert, m.vi.mode)
+	assert.Contains(t, m.statusText(), "INSERT · pgxcli")
+}
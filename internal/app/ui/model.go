@@ -4,19 +4,19 @@ package ui
 import (
 	"bytes"
 	"fmt"
-	"os"
 	"os/exec"
-	"path/filepath"
 	"strings"
+	"time"
 
 	"charm.land/bubbles/v2/key"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 	"github.com/Balaji01-4D/bubbline/computil"
 	"github.com/Balaji01-4D/bubbline/editline"
-	"github.com/Balaji01-4D/bubbline/history"
 	"github.com/alecthomas/chroma/v2/quick"
 	"github.com/balaji01-4d/pgxcli/internal/config"
+	"github.com/balaji01-4d/pgxcli/internal/history"
+	"github.com/balaji01-4d/pgxcli/internal/parser"
 	"github.com/muesli/termenv"
 )
 
@@ -33,11 +33,115 @@ var (
 )
 
 // ReadyMsg signals the ui that execution is done and it should prompt.
-type ReadyMsg struct{ Prefix string }
+// Status, when set, replaces the text shown in the status bar.
+type ReadyMsg struct {
+	Prefix string
+	Status string
+	// Session, when set, is the connection later queries run against.
+	Session Session
+	// Outcome is recorded in the history entry of the input just run.
+	Outcome Outcome
+}
+
+// Session identifies the connection statements run against.
+type Session struct {
+	Host     string
+	Database string
+	User     string
+}
+
+// Outcome reports how an input ran: the total query time, the rows returned
+// or affected, and the last error if any statement failed.
+type Outcome struct {
+	Duration time.Duration
+	Rows     int64
+	Err      error
+}
 
 // ExecCmdMsg is used to dispatch a batch/sequence of commands.
 type ExecCmdMsg struct{ Cmd tea.Cmd }
 
+// WatchMsg starts re-running a query every Interval until ctrl+c is pressed
+// or Count iterations have run (zero means no limit). Run renders one
+// iteration; its output is redrawn in place rather than printed. Stop, when
+// set, cancels the iteration running when ctrl+c is pressed, and is called
+// once the watch has ended. Done hands the prompt back once the watch has
+// ended; when nil, the prompt is shown as it was.
+type WatchMsg struct {
+	Interval time.Duration
+	Count    int
+	Run      func() (string, error)
+	Stop     func()
+	Done     tea.Cmd
+}
+
+// SettingsMsg changes the settings of the session, such as after the config
+// file was edited. It is applied once the input being run, if any, is done,
+// so settings never change under a running command. Apply makes the change
+// and returns how the prompt looks from then on.
+type SettingsMsg struct {
+	Apply func() Settings
+}
+
+// Settings are the prompt and highlighting style to use after a
+// SettingsMsg; empty fields are left unchanged. Notice, when set, is printed.
+type Settings struct {
+	Prefix string
+	Style  string
+	Notice string
+}
+
+// ProgressMsg reports progress of a long-running command. The text is shown
+// in the status bar until the next ReadyMsg.
+type ProgressMsg struct{ Text string }
+
+// ConfirmMsg asks a yes/no question in place of the prompt. Answering "y"
+// runs OnConfirm; any other answer runs OnCancel.
+type ConfirmMsg struct {
+	Question  string
+	OnConfirm tea.Cmd
+	OnCancel  tea.Cmd
+}
+
+// PromptMsg asks for a line of input in place of the prompt, such as a
+// password. Secret input is not echoed. Enter runs OnSubmit with the value;
+// esc or ctrl+c runs OnCancel.
+type PromptMsg struct {
+	Question string
+	Secret   bool
+	OnSubmit func(string) tea.Cmd
+	OnCancel tea.Cmd
+}
+
+type promptState struct {
+	PromptMsg
+	value []rune
+}
+
+type watchState struct {
+	id        int
+	interval  time.Duration
+	count     int
+	iteration int
+	output    string
+	run       func() (string, error)
+	stop      func()
+	done      tea.Cmd
+
+	// running is set while an iteration runs; stopping once ctrl+c was
+	// pressed during it.
+	running  bool
+	stopping bool
+}
+
+type watchTickMsg struct{ id int }
+
+type watchResultMsg struct {
+	id     int
+	output string
+	err    error
+}
+
 type Model struct {
 	input         *editline.Model
 	width, height int
@@ -45,28 +149,61 @@ type Model struct {
 	prevUserInput string
 	historyFile   string
 	style         string
+	status        string
+	progress      string
+
+	watch    *watchState
+	watchSeq int
+
+	confirm *ConfirmMsg
+	prompt  *promptState
+
+	// viewer is the open result viewer; results that arrive while it is
+	// open wait in viewerQueue.
+	viewer      *viewer
+	viewerQueue []ViewerMsg
+
+	// vi is set when vi_mode is enabled.
+	vi *viState
+
+	// history holds the entries of the history file, oldest first. pending
+	// is the entry of the input being run, recorded once it is done. Only
+	// the entries in scope for the session are offered for recall.
+	history     []history.Entry
+	pending     *history.Entry
+	session     Session
+	historyOpts HistoryOptions
+
+	// search is the open ctrl+r history search.
+	search *historySearch
+
+	// pendingSettings wait for the input being run to finish.
+	pendingSettings []SettingsMsg
 
 	// execute executes a query passed and return as ExecCmdMsg + ReadyMsg.
 	execute func(string) tea.Cmd
 }
 
-func New(initialPrefix string, pgKeywords []string, historyFile string, style string, executeFunc func(string) tea.Cmd) (*Model, error) {
+func New(initialPrefix, initialStatus string, pgKeywords []string, historyFile string, style string, keys config.KeysConfig, viMode bool, executeFunc func(string) tea.Cmd) (*Model, error) {
 	el := editline.New(0, 0)
 	el.Prompt = initialPrefix
-	if historyFile == "" || historyFile == config.Default {
-		historyFile = getHistoryFilePath()
-	}
-
-	if err := applyEditlineConfig(el, historyFile, pgKeywords, style); err != nil {
-		return nil, fmt.Errorf("applying input config: %w", err)
-	}
+	applyEditlineConfig(el, pgKeywords, style)
+	applyKeyBindings(&el.KeyMap, keys)
 
-	return &Model{
+	m := &Model{
 		input:       el,
 		historyFile: historyFile,
 		style:       style,
+		status:      initialStatus,
 		execute:     executeFunc,
-	}, nil
+	}
+	if err := m.loadHistory(); err != nil {
+		return nil, fmt.Errorf("loading history: %w", err)
+	}
+	if viMode {
+		m.vi = &viState{}
+	}
+	return m, nil
 }
 
 func (m *Model) Init() tea.Cmd {
@@ -77,16 +214,29 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 
 	case ReadyMsg:
-		m.executing = false
-		if msg.Prefix != "" {
-			m.input.Prompt = msg.Prefix
-		}
-		m.input.Reset()
-		return m, nil
+		return m, m.ready(msg)
 
 	case ExecCmdMsg:
 		return m, msg.Cmd
 
+	case ProgressMsg:
+		m.progress = msg.Text
+		return m, nil
+
+	case SettingsMsg:
+		if m.executing {
+			m.pendingSettings = append(m.pendingSettings, msg)
+			return m, nil
+		}
+		return m, m.applySettings(msg)
+
+	case WatchMsg, watchTickMsg, watchResultMsg:
+		return m, m.updateWatch(msg)
+
+	case ConfirmMsg, PromptMsg, ViewerMsg:
+		m.showOverlay(msg)
+		return m, nil
+
 	case editline.InputCompleteMsg:
 		return m.handleInput()
 
@@ -94,17 +244,17 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 		m.input.SetSize(msg.Width, msg.Height-4)
+		if m.viewer != nil {
+			m.viewer.resize(msg.Width, msg.Height)
+		}
 		return m, nil
 
 	case tea.KeyMsg:
-		if m.executing {
-			return m, nil
+		if cmd, handled := m.handleKey(msg); handled {
+			return m, cmd
 		}
-
-		switch msg.String() {
-		case "ctrl+c":
-			m.input.Reset()
-			return m, nil
+		if cmd, handled := m.handleViKey(msg); handled {
+			return m, cmd
 		}
 	}
 
@@ -113,6 +263,161 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nextCmd
 }
 
+// ready ends execution, records the input in the history and resets the
+// input for the next statement.
+func (m *Model) ready(msg ReadyMsg) tea.Cmd {
+	err := m.recordHistory(msg.Outcome)
+	m.executing = false
+	m.progress = ""
+	if msg.Prefix != "" {
+		m.input.Prompt = msg.Prefix
+	}
+	if msg.Status != "" {
+		m.status = msg.Status
+	}
+	if msg.Session != (Session{}) && msg.Session != m.session {
+		// e.g. after \c, recall the history of the new connection
+		m.session = msg.Session
+		m.refreshHistory()
+	}
+	if m.vi != nil {
+		// each new statement starts in insert mode, like readline's vi mode
+		*m.vi = viState{}
+	}
+	m.input.Reset()
+
+	var cmds []tea.Cmd
+	if err != nil {
+		cmds = append(cmds, PrintErrCmd(fmt.Errorf("saving history: %w", err)))
+	}
+	for _, settings := range m.pendingSettings {
+		if cmd := m.applySettings(settings); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	}
+	m.pendingSettings = nil
+	if len(cmds) == 0 {
+		return nil
+	}
+	return tea.Sequence(cmds...)
+}
+
+// applySettings applies a settings change to the prompt and the highlighting
+// of the input.
+func (m *Model) applySettings(msg SettingsMsg) tea.Cmd {
+	settings := msg.Apply()
+	if settings.Prefix != "" {
+		m.input.Prompt = settings.Prefix
+	}
+	if settings.Style != "" && settings.Style != m.style {
+		m.style = settings.Style
+		m.input.SetHighlighter(postgresHighlighter(settings.Style))
+	}
+	if settings.Notice != "" {
+		return PrintCmd(settings.Notice)
+	}
+	return nil
+}
+
+// showOverlay replaces the prompt with a question or the result viewer.
+func (m *Model) showOverlay(msg tea.Msg) {
+	switch msg := msg.(type) {
+	case ConfirmMsg:
+		m.confirm = &msg
+	case PromptMsg:
+		m.prompt = &promptState{PromptMsg: msg}
+	case ViewerMsg:
+		if m.viewer != nil {
+			m.viewerQueue = append(m.viewerQueue, msg)
+		} else {
+			m.viewer = newViewer(msg, m.width, m.height)
+		}
+	}
+}
+
+// handleKey intercepts keys that must not reach the input editor.
+func (m *Model) handleKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch {
+	case m.viewer != nil:
+		return m.updateViewer(msg), true
+	case m.confirm != nil:
+		return m.answerConfirm(msg.String()), true
+	case m.prompt != nil:
+		return m.answerPrompt(msg), true
+	case m.search != nil:
+		return m.answerHistorySearch(msg), true
+	case m.watch != nil && msg.String() == "ctrl+c":
+		return m.interruptWatch(), true
+	case m.executing:
+		return nil, true
+	case key.Matches(msg, m.input.KeyMap.ReverseSearch):
+		m.openHistorySearch()
+		return nil, true
+	case msg.String() == "ctrl+c":
+		m.input.Reset()
+		return nil, true
+	}
+	return nil, false
+}
+
+// updateViewer passes a key to the result viewer. Once it is closed, its
+// footer is printed and the next queued result, if any, is shown.
+func (m *Model) updateViewer(msg tea.KeyMsg) tea.Cmd {
+	cmd, closed := m.viewer.update(msg)
+	if !closed {
+		return cmd
+	}
+
+	cmds := []tea.Cmd{cmd}
+	if m.viewer.footer != "" {
+		cmds = append(cmds, PrintCmd(m.viewer.footer))
+	}
+	m.viewer = nil
+	if len(m.viewerQueue) > 0 {
+		m.viewer = newViewer(m.viewerQueue[0], m.width, m.height)
+		m.viewerQueue = m.viewerQueue[1:]
+	}
+	return tea.Sequence(cmds...)
+}
+
+func (m *Model) answerConfirm(key string) tea.Cmd {
+	c := m.confirm
+	switch key {
+	case "y", "Y":
+		m.confirm = nil
+		return tea.Sequence(PrintCmd(c.Question+" y"), c.OnConfirm)
+	case "n", "N", "enter", "esc", "ctrl+c":
+		m.confirm = nil
+		return tea.Sequence(PrintCmd(c.Question+" n"), c.OnCancel)
+	}
+	return nil
+}
+
+func (m *Model) answerPrompt(msg tea.KeyMsg) tea.Cmd {
+	p := m.prompt
+	switch msg.String() {
+	case "enter":
+		m.prompt = nil
+		echo := p.Question + ": "
+		if !p.Secret {
+			echo += string(p.value)
+		}
+		return tea.Sequence(PrintCmd(echo), p.OnSubmit(string(p.value)))
+	case "esc", "ctrl+c":
+		m.prompt = nil
+		return tea.Sequence(PrintCmd(p.Question+":"), p.OnCancel)
+	case "backspace":
+		if len(p.value) > 0 {
+			p.value = p.value[:len(p.value)-1]
+		}
+		return nil
+	}
+	if press, ok := msg.(tea.KeyPressMsg); ok {
+		p.value = append(p.value, []rune(press.Key().Text)...)
+	}
+	return nil
+}
+
 func (m *Model) handleInput() (tea.Model, tea.Cmd) {
 	input := m.input.Value()
 	trimmed := strings.TrimSpace(input)
@@ -126,9 +431,9 @@ func (m *Model) handleInput() (tea.Model, tea.Cmd) {
 		)
 	}
 
-	m.prevUserInput = input
+	m.prevUserInput = previousQuery(m.prevUserInput, input)
 	m.executing = true
-	m.input.AddHistoryEntry(input)
+	m.addHistory(input)
 
 	return m, tea.Sequence(
 		m.printUserInput(userInputStyle.Render(m.input.Prompt), input),
@@ -136,6 +441,184 @@ func (m *Model) handleInput() (tea.Model, tea.Cmd) {
 	)
 }
 
+// previousQuery returns the query \watch and \parse use after input.
+// Backslash commands are not queries, so they must not replace it; a query
+// ending with one, e.g. "SELECT $1 \parse q", leaves just the query.
+func previousQuery(prev, input string) string {
+	switch query, meta := parser.SplitMetaCommand(input); {
+	case meta == "":
+		return input
+	case query != "":
+		return query
+	default:
+		return prev
+	}
+}
+
+// SetSession sets the connection recorded with the queries run from now on.
+func (m *Model) SetSession(s Session) {
+	m.session = s
+	m.refreshHistory()
+}
+
+// HistoryOptions controls what is saved to the history and recalled from it.
+type HistoryOptions struct {
+	// Scope selects the entries offered for recall.
+	Scope config.HistoryScope
+	// IgnoreSpace keeps inputs starting with a space out of the history.
+	IgnoreSpace bool
+	// Redact, when set, masks secrets in inputs before they are saved.
+	Redact func(string) string
+}
+
+// SetHistoryOptions sets what is saved to the history and recalled from it.
+func (m *Model) SetHistoryOptions(opts HistoryOptions) {
+	m.historyOpts = opts
+	m.refreshHistory()
+}
+
+// scopedHistory returns the history entries in scope for the session.
+func (m *Model) scopedHistory() []history.Entry {
+	return history.Scoped(m.history, m.historyOpts.Scope, m.session.Host, m.session.Database)
+}
+
+// addHistory starts the history entry of input, which is recorded once it
+// has run. Secrets are masked even in the history recalled in this session.
+func (m *Model) addHistory(input string) {
+	if m.historyOpts.IgnoreSpace && strings.HasPrefix(input, " ") {
+		return
+	}
+	if m.historyOpts.Redact != nil {
+		input = m.historyOpts.Redact(input)
+	}
+	m.input.AddHistoryEntry(input)
+	m.pending = &history.Entry{
+		Query:    input,
+		Time:     time.Now(),
+		Host:     m.session.Host,
+		Database: m.session.Database,
+		User:     m.session.User,
+	}
+}
+
+// refreshHistory hands the editor the queries in scope for the session.
+func (m *Model) refreshHistory() {
+	entries := m.scopedHistory()
+	queries := make([]string, len(entries))
+	for i, entry := range entries {
+		queries[i] = entry.Query
+	}
+	m.input.SetHistory(queries)
+}
+
+// PrevUserInput returns the last query entered, ignoring backslash commands.
+func (m *Model) PrevUserInput() string {
+	return m.prevUserInput
+}
+
+func (m *Model) updateWatch(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case WatchMsg:
+		m.watchSeq++
+		m.watch = &watchState{
+			id:       m.watchSeq,
+			interval: msg.Interval,
+			count:    msg.Count,
+			run:      msg.Run,
+			stop:     msg.Stop,
+			done:     msg.Done,
+		}
+		return m.runWatch()
+
+	case watchTickMsg:
+		if m.watch == nil || msg.id != m.watch.id {
+			return nil
+		}
+		return m.runWatch()
+
+	case watchResultMsg:
+		if m.watch == nil || msg.id != m.watch.id {
+			return nil
+		}
+		m.watch.running = false
+		if m.watch.stopping {
+			// the error is the cancellation asked for
+			return m.stopWatch(nil)
+		}
+		if msg.err != nil {
+			return m.stopWatch(msg.err)
+		}
+		m.watch.iteration++
+		m.watch.output = fmt.Sprintf("%s (every %s)\n\n%s",
+			time.Now().Format(time.ANSIC), m.watch.interval, msg.output)
+		if m.watch.count > 0 && m.watch.iteration >= m.watch.count {
+			return m.stopWatch(nil)
+		}
+		id := m.watch.id
+		return tea.Tick(m.watch.interval, func(time.Time) tea.Msg {
+			return watchTickMsg{id: id}
+		})
+	}
+	return nil
+}
+
+func (m *Model) runWatch() tea.Cmd {
+	w := m.watch
+	w.running = true
+	return func() tea.Msg {
+		output, err := w.run()
+		return watchResultMsg{id: w.id, output: output, err: err}
+	}
+}
+
+// interruptWatch stops the watch on ctrl+c. An iteration still running is
+// canceled, and the prompt only comes back once it has returned, so the next
+// input never shares the connection with it.
+func (m *Model) interruptWatch() tea.Cmd {
+	if !m.watch.running {
+		return m.stopWatch(nil)
+	}
+	if !m.watch.stopping {
+		m.watch.stopping = true
+		if m.watch.stop != nil {
+			m.watch.stop()
+		}
+	}
+	return nil
+}
+
+// stopWatch ends the active watch, leaving its last output in the scrollback.
+func (m *Model) stopWatch(err error) tea.Cmd {
+	if m.watch.stop != nil {
+		m.watch.stop()
+	}
+	cmds := make([]tea.Cmd, 0, 3)
+	if m.watch.output != "" {
+		cmds = append(cmds, PrintCmd(m.watch.output))
+	}
+	if err != nil {
+		cmds = append(cmds, PrintErrCmd(err))
+	}
+	done := m.watch.done
+	m.watch = nil
+	if done == nil {
+		prompt := m.input.Prompt
+		done = func() tea.Msg {
+			return ReadyMsg{Prefix: prompt}
+		}
+	}
+	cmds = append(cmds, done)
+	return tea.Sequence(cmds...)
+}
+
+func (m *Model) watchView() string {
+	lines := strings.Split(m.watch.output, "\n")
+	if m.height > 1 && len(lines) > m.height-1 {
+		lines = lines[:m.height-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
 func (m *Model) printUserInput(prefix, input string) tea.Cmd {
 	var highlightedInput string
 	if input != "" {
@@ -147,25 +630,98 @@ func (m *Model) printUserInput(prefix, input string) tea.Cmd {
 }
 
 func (m *Model) View() tea.View {
+	if m.viewer != nil {
+		view := tea.NewView(m.viewer.view())
+		view.AltScreen = true
+		return view
+	}
+
 	statusStyle := statusBarStyle.Width(m.width)
+	if m.confirm != nil {
+		question := userInputStyle.Render(m.confirm.Question + " ")
+		return tea.NewView(lipgloss.Sprintf("%s\n%s", question, statusStyle.Render(m.statusText())))
+	}
+	if m.prompt != nil {
+		line := m.prompt.Question + ": "
+		if !m.prompt.Secret {
+			line += string(m.prompt.value)
+		}
+		return tea.NewView(lipgloss.Sprintf("%s\n%s", userInputStyle.Render(line), statusStyle.Render(m.statusText())))
+	}
+	if m.search != nil {
+		status := m.statusText() + " · ↑/↓ select · enter use · esc cancel"
+		return tea.NewView(lipgloss.Sprintf("%s\n%s", m.historySearchView(), statusStyle.Render(status)))
+	}
+	if m.watch != nil {
+		status := fmt.Sprintf("%s · watching every %s, ctrl+c to stop", m.statusText(), m.watch.interval)
+		if m.watch.stopping {
+			status = m.statusText() + " · stopping watch"
+		}
+		return tea.NewView(lipgloss.Sprintf("%s\n%s", m.watchView(), statusStyle.Render(status)))
+	}
 	if m.executing {
-		statusBar := statusStyle.AlignVertical(lipgloss.Bottom).Render("pgxcli")
+		status := m.statusText()
+		if m.progress != "" {
+			status += " · " + m.progress
+		}
+		statusBar := statusStyle.AlignVertical(lipgloss.Bottom).Render(status)
 		return tea.NewView(statusBar)
 	}
 
-	str := lipgloss.Sprintf("%s\n%s", m.input.View(), statusStyle.Render("pgxcli"))
+	str := lipgloss.Sprintf("%s\n%s", m.input.View(), statusStyle.Render(m.statusText()))
 	return tea.NewView(str)
 }
 
-func (m *Model) saveHistory() error {
+func (m *Model) statusText() string {
+	status := m.status
+	if status == "" {
+		status = "pgxcli"
+	}
+	if m.vi != nil {
+		status = m.vi.label() + " · " + status
+	}
+	return status
+}
+
+// loadHistory reads the history file and hands its queries to the editor.
+func (m *Model) loadHistory() error {
+	if m.historyFile == "" {
+		return nil
+	}
+	entries, err := history.Load(m.historyFile)
+	if err != nil {
+		return err
+	}
+	m.history = entries
+	m.refreshHistory()
+	return nil
+}
+
+// recordHistory completes the entry of the input just run with its outcome
+// and appends it to the history file.
+func (m *Model) recordHistory(outcome Outcome) error {
+	if m.pending == nil {
+		return nil
+	}
+	entry := *m.pending
+	m.pending = nil
+
+	entry.Duration = outcome.Duration
+	entry.Rows = outcome.Rows
+	if outcome.Err != nil {
+		entry.Error = outcome.Err.Error()
+	}
+	m.history = append(m.history, entry)
 	if m.historyFile == "" {
 		return nil
 	}
-	return history.SaveHistory(m.input.GetHistory(), m.historyFile)
+	return history.Append(m.historyFile, entry)
 }
 
+// Close records the input still running, such as the \q that ended the
+// session. Other entries are saved as soon as they complete.
 func (m *Model) Close() error {
-	if err := m.saveHistory(); err != nil {
+	if err := m.recordHistory(Outcome{}); err != nil {
 		return fmt.Errorf("saving history: %w", err)
 	}
 	return nil
@@ -242,7 +798,7 @@ func detectTerminalColorProfile() string {
 	}
 }
 
-func applyEditlineConfig(el *editline.Model, historyFile string, pgKeywords []string, style string) error {
+func applyEditlineConfig(el *editline.Model, pgKeywords []string, style string) {
 	el.SetHelpDisabled(true)
 	el.SetHighlighter(postgresHighlighter(style))
 	el.SetExternalEditorEnabled(true, "sql")
@@ -251,20 +807,4 @@ func applyEditlineConfig(el *editline.Model, historyFile string, pgKeywords []st
 		key.WithHelp("ctrl+e", "edit query in external editor"),
 	)
 	el.AutoComplete = postgresAutocomplete(pgKeywords)
-
-	entries, err := history.LoadHistory(historyFile)
-	if err != nil {
-		return fmt.Errorf("loading history: %w", err)
-	}
-
-	el.SetHistory(entries)
-	return nil
-}
-
-func getHistoryFilePath() string {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return ""
-	}
-	return filepath.Join(homeDir, ".pgxcli_history.jsonl")
 }
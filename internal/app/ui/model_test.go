@@ -0,0 +1,130 @@
+package ui
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/Balaji01-4D/bubbline/editline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettingsWaitForRunningInput(t *testing.T) {
+	m := &Model{input: editline.New(0, 0), style: "monokai", executing: true}
+	m.input.Prompt = "old> "
+
+	applied := 0
+	msg := SettingsMsg{Apply: func() Settings {
+		applied++
+		return Settings{Prefix: "new> ", Style: "dracula", Notice: "Config reloaded"}
+	}}
+	_, cmd := m.Update(msg)
+	assert.Nil(t, cmd)
+	assert.Zero(t, applied)
+	assert.Equal(t, "old> ", m.input.Prompt)
+
+	cmd = m.ready(ReadyMsg{Prefix: "old> "})
+	assert.NotNil(t, cmd)
+	assert.Equal(t, 1, applied)
+	assert.Equal(t, "new> ", m.input.Prompt)
+	assert.Equal(t, "dracula", m.style)
+	assert.Empty(t, m.pendingSettings)
+
+	_, cmd = m.Update(SettingsMsg{Apply: func() Settings {
+		applied++
+		return Settings{}
+	}})
+	assert.Nil(t, cmd)
+	assert.Equal(t, 2, applied)
+	assert.Equal(t, "new> ", m.input.Prompt)
+}
+
+func TestWatchInterruptWaitsForRunningIteration(t *testing.T) {
+	m := &Model{input: editline.New(0, 0), executing: true}
+	m.input.Prompt = "db> "
+	ctrlC := tea.KeyPressMsg{Code: 'c', Mod: tea.ModCtrl}
+
+	stopped := 0
+	_, cmd := m.Update(WatchMsg{
+		Interval: time.Second,
+		Run:      func() (string, error) { return "1", nil },
+		Stop:     func() { stopped++ },
+	})
+	require.NotNil(t, cmd)
+	require.True(t, m.watch.running)
+
+	// ctrl+c during an iteration cancels it but keeps the watch until it returns
+	_, cmd = m.Update(ctrlC)
+	assert.Nil(t, cmd)
+	assert.Equal(t, 1, stopped)
+	require.NotNil(t, m.watch)
+	assert.True(t, m.watch.stopping)
+
+	_, cmd = m.Update(watchResultMsg{id: m.watch.id, err: context.Canceled})
+	assert.NotNil(t, cmd)
+	assert.Nil(t, m.watch)
+	assert.Equal(t, 2, stopped, "Stop is called again once the watch has ended")
+
+	// between iterations the watch ends at once
+	_, _ = m.Update(WatchMsg{Interval: time.Second, Run: func() (string, error) { return "1", nil }, Stop: func() { stopped++ }})
+	_, cmd = m.Update(watchResultMsg{id: m.watch.id, output: "1"})
+	require.NotNil(t, cmd)
+	require.False(t, m.watch.running)
+	_, cmd = m.Update(ctrlC)
+	assert.NotNil(t, cmd)
+	assert.Nil(t, m.watch)
+	assert.Equal(t, 3, stopped)
+}
+
+func TestWatchEndsThroughDone(t *testing.T) {
+	m := &Model{input: editline.New(0, 0), executing: true}
+	ready := ReadyMsg{Prefix: "app> "}
+
+	_, _ = m.Update(WatchMsg{
+		Interval: time.Second,
+		Count:    1,
+		Run:      func() (string, error) { return "1", nil },
+		Done:     func() tea.Msg { return ready },
+	})
+	_, cmd := m.Update(watchResultMsg{id: m.watch.id, output: "1"})
+	require.NotNil(t, cmd)
+	assert.Nil(t, m.watch)
+	assert.Contains(t, sequenceMsgs(cmd), tea.Msg(ready))
+}
+
+// sequenceMsgs runs the commands of a tea.Sequence and returns their messages.
+func sequenceMsgs(cmd tea.Cmd) []tea.Msg {
+	msg := cmd()
+	seq := reflect.ValueOf(msg)
+	if seq.Kind() != reflect.Slice {
+		return []tea.Msg{msg}
+	}
+	var msgs []tea.Msg
+	for i := range seq.Len() {
+		if c, ok := seq.Index(i).Interface().(tea.Cmd); ok && c != nil {
+			msgs = append(msgs, c())
+		}
+	}
+	return msgs
+}
+
+func TestPreviousQuery(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "query", input: "SELECT 1", want: "SELECT 1"},
+		{name: "meta-command", input: `\dt`, want: "SELECT 0"},
+		{name: "query ending with meta-command", input: `SELECT $1 \parse q`, want: "SELECT $1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, previousQuery("SELECT 0", tc.input))
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package ui
+
+import (
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+type viMode int
+
+const (
+	viInsert viMode = iota
+	viNormal
+)
+
+// viState tracks modal editing when vi_mode is enabled. Normal mode
+// commands are carried out by sending the editor the keys currently bound to
+// the equivalent editing actions, so they follow any [keys] rebinding.
+type viState struct {
+	mode viMode
+	// pending holds the keys of a command typed so far, e.g. "d" or "ci".
+	pending string
+}
+
+// viCommand is a normal mode command: editing actions run in order, then
+// optionally a switch to insert mode.
+type viCommand struct {
+	actions []string
+	insert  bool
+}
+
+var viCommands = map[string]viCommand{
+	"h":   {actions: []string{"character_backward"}},
+	"l":   {actions: []string{"character_forward"}},
+	"k":   {actions: []string{"line_previous"}},
+	"j":   {actions: []string{"line_next"}},
+	"w":   {actions: []string{"word_forward"}},
+	"e":   {actions: []string{"word_forward"}},
+	"b":   {actions: []string{"word_backward"}},
+	"0":   {actions: []string{"line_start"}},
+	"^":   {actions: []string{"line_start"}},
+	"$":   {actions: []string{"line_end"}},
+	"gg":  {actions: []string{"input_begin"}},
+	"G":   {actions: []string{"input_end"}},
+	"x":   {actions: []string{"delete_character_forward"}},
+	"X":   {actions: []string{"delete_character_backward"}},
+	"D":   {actions: []string{"delete_after_cursor"}},
+	"dd":  {actions: []string{"line_start", "delete_after_cursor"}},
+	"d$":  {actions: []string{"delete_after_cursor"}},
+	"d0":  {actions: []string{"delete_before_cursor"}},
+	"dw":  {actions: []string{"delete_word_forward"}},
+	"de":  {actions: []string{"delete_word_forward"}},
+	"db":  {actions: []string{"delete_word_backward"}},
+	"diw": {actions: []string{"word_forward", "word_backward", "delete_word_forward"}},
+	"i":   {insert: true},
+	"a":   {actions: []string{"character_forward"}, insert: true},
+	"A":   {actions: []string{"line_end"}, insert: true},
+	"I":   {actions: []string{"line_start"}, insert: true},
+	"s":   {actions: []string{"delete_character_forward"}, insert: true},
+	"S":   {actions: []string{"line_start", "delete_after_cursor"}, insert: true},
+	"C":   {actions: []string{"delete_after_cursor"}, insert: true},
+	"cc":  {actions: []string{"line_start", "delete_after_cursor"}, insert: true},
+	"c$":  {actions: []string{"delete_after_cursor"}, insert: true},
+	"cw":  {actions: []string{"delete_word_forward"}, insert: true},
+	"ce":  {actions: []string{"delete_word_forward"}, insert: true},
+	"cb":  {actions: []string{"delete_word_backward"}, insert: true},
+	"ciw": {actions: []string{"word_forward", "word_backward", "delete_word_forward"}, insert: true},
+}
+
+func (v *viState) label() string {
+	if v.mode == viNormal {
+		return "NORMAL"
+	}
+	return "INSERT"
+}
+
+// handleViKey applies vi mode to a key press. Keys it does not handle go to
+// the editor as usual.
+func (m *Model) handleViKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	if m.vi == nil {
+		return nil, false
+	}
+	if m.vi.mode == viInsert {
+		if msg.String() != "esc" {
+			return nil, false
+		}
+		// like vi, leaving insert mode steps back onto the last character
+		m.vi.mode = viNormal
+		return m.runEditActions("character_backward"), true
+	}
+	return m.normalModeKey(msg)
+}
+
+func (m *Model) normalModeKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	k := msg.String()
+	press, ok := msg.(tea.KeyPressMsg)
+	if !ok || press.Key().Text == "" {
+		// enter, arrows and control keys keep working in normal mode
+		m.vi.pending = ""
+		return nil, false
+	}
+
+	m.vi.pending += k
+	cmd, found := viCommands[m.vi.pending]
+	if !found {
+		if !isViPrefix(m.vi.pending) {
+			m.vi.pending = ""
+		}
+		return nil, true
+	}
+
+	m.vi.pending = ""
+	if cmd.insert {
+		m.vi.mode = viInsert
+	}
+	return m.runEditActions(cmd.actions...), true
+}
+
+// isViPrefix reports whether keys can still become a normal mode command.
+func isViPrefix(keys string) bool {
+	for command := range viCommands {
+		if strings.HasPrefix(command, keys) {
+			return true
+		}
+	}
+	return false
+}
+
+// runEditActions sends the editor the first key bound to each action.
+func (m *Model) runEditActions(actions ...string) tea.Cmd {
+	bindings := keyBindings(&m.input.KeyMap)
+	cmds := make([]tea.Cmd, 0, len(actions))
+	for _, action := range actions {
+		keys := bindings[action].Keys()
+		if len(keys) == 0 {
+			continue
+		}
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(keyPress(keys[0]))
+		cmds = append(cmds, cmd)
+	}
+	return tea.Batch(cmds...)
+}
@@ -0,0 +1,494 @@
+package ui
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	// maxViewerColumnWidth caps a column's width in the result viewer; longer
+	// values are truncated on screen but copied in full.
+	maxViewerColumnWidth = 40
+	// viewerTextScroll is how many characters left and right scroll text.
+	viewerTextScroll = 8
+	viewerColumnGap  = " │ "
+)
+
+var (
+	viewerHeaderStyle = lipgloss.NewStyle().Bold(true).Underline(true).Foreground(lipgloss.Color("#E0DEF4"))
+	viewerCursorStyle = lipgloss.NewStyle().Reverse(true)
+	viewerMatchStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#F6C177"))
+)
+
+// ViewerMsg opens the built-in result viewer, which takes over the screen
+// until it is closed. Without Columns, each row holds one line of
+// preformatted text. Footer, such as the command tag, is printed once the
+// viewer closes.
+type ViewerMsg struct {
+	Columns []string
+	Rows    [][]string
+	Footer  string
+}
+
+// TextViewerMsg returns a ViewerMsg that pages through text.
+func TextViewerMsg(text string) ViewerMsg {
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	rows := make([][]string, len(lines))
+	for i, line := range lines {
+		rows[i] = []string{line}
+	}
+	return ViewerMsg{Rows: rows}
+}
+
+// ShowViewerCmd returns a command that opens the built-in result viewer.
+func ShowViewerCmd(msg ViewerMsg) tea.Cmd {
+	return func() tea.Msg {
+		return msg
+	}
+}
+
+// viewer is the state of the built-in result viewer. Rows keep their raw
+// values; control characters are only replaced when drawn.
+type viewer struct {
+	columns []string
+	rows    [][]string
+	widths  []int
+	// order lists the visible columns in display order.
+	order  []int
+	text   bool
+	footer string
+
+	// row and col are the cursor; col indexes order.
+	row, col int
+	// top is the first row shown. left is the first column shown, or the
+	// first character for text.
+	top, left     int
+	width, height int
+
+	sortCol  int
+	sortDesc bool
+	sorted   bool
+
+	search    string
+	searching bool
+	input     []rune
+	notice    string
+}
+
+func newViewer(msg ViewerMsg, width, height int) *viewer {
+	v := &viewer{
+		columns: msg.Columns,
+		rows:    make([][]string, len(msg.Rows)),
+		text:    len(msg.Columns) == 0,
+		footer:  msg.Footer,
+	}
+
+	ncols := max(len(msg.Columns), 1)
+	v.widths = make([]int, ncols)
+	for i, name := range msg.Columns {
+		v.widths[i] = ansi.StringWidth(name)
+	}
+	for i, row := range msg.Rows {
+		cells := make([]string, ncols)
+		copy(cells, row)
+		for j, cell := range cells {
+			v.widths[j] = max(v.widths[j], ansi.StringWidth(sanitizeCell(cell)))
+		}
+		v.rows[i] = cells
+	}
+	if !v.text {
+		for i, w := range v.widths {
+			v.widths[i] = min(w, maxViewerColumnWidth)
+		}
+	}
+
+	v.order = allColumns(ncols)
+	v.resize(width, height)
+	return v
+}
+
+func allColumns(n int) []int {
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	return order
+}
+
+// sanitizeCell keeps multi-line values on one screen line.
+func sanitizeCell(s string) string {
+	return strings.NewReplacer("\r\n", "↵", "\n", "↵", "\r", "↵", "\t", "    ").Replace(s)
+}
+
+func (v *viewer) resize(width, height int) {
+	v.width, v.height = width, height
+	v.scrollToCursor()
+}
+
+// bodyHeight is the number of rows shown below the header.
+func (v *viewer) bodyHeight() int {
+	h := v.height - 1 // status bar
+	if !v.text {
+		h-- // header
+	}
+	return max(h, 1)
+}
+
+type viewerAction func(v *viewer) tea.Cmd
+
+var viewerKeys = map[string]viewerAction{
+	"up":     func(v *viewer) tea.Cmd { return v.moveRow(-1) },
+	"k":      func(v *viewer) tea.Cmd { return v.moveRow(-1) },
+	"down":   func(v *viewer) tea.Cmd { return v.moveRow(1) },
+	"j":      func(v *viewer) tea.Cmd { return v.moveRow(1) },
+	"pgup":   func(v *viewer) tea.Cmd { return v.moveRow(-v.bodyHeight()) },
+	"b":      func(v *viewer) tea.Cmd { return v.moveRow(-v.bodyHeight()) },
+	"pgdown": func(v *viewer) tea.Cmd { return v.moveRow(v.bodyHeight()) },
+	"space":  func(v *viewer) tea.Cmd { return v.moveRow(v.bodyHeight()) },
+	"home":   func(v *viewer) tea.Cmd { return v.moveRow(-len(v.rows)) },
+	"g":      func(v *viewer) tea.Cmd { return v.moveRow(-len(v.rows)) },
+	"end":    func(v *viewer) tea.Cmd { return v.moveRow(len(v.rows)) },
+	"G":      func(v *viewer) tea.Cmd { return v.moveRow(len(v.rows)) },
+	"left":   func(v *viewer) tea.Cmd { return v.moveCol(-1) },
+	"h":      func(v *viewer) tea.Cmd { return v.moveCol(-1) },
+	"right":  func(v *viewer) tea.Cmd { return v.moveCol(1) },
+	"l":      func(v *viewer) tea.Cmd { return v.moveCol(1) },
+	"0":      func(v *viewer) tea.Cmd { return v.moveCol(-len(v.order)) },
+	"$":      func(v *viewer) tea.Cmd { return v.moveCol(len(v.order)) },
+	"x":      (*viewer).hideColumn,
+	"u":      (*viewer).showAllColumns,
+	"<":      func(v *viewer) tea.Cmd { return v.moveColumn(-1) },
+	">":      func(v *viewer) tea.Cmd { return v.moveColumn(1) },
+	"s":      (*viewer).sortByColumn,
+	"/":      (*viewer).startSearch,
+	"n":      func(v *viewer) tea.Cmd { return v.findNext(1) },
+	"N":      func(v *viewer) tea.Cmd { return v.findNext(-1) },
+	"y":      (*viewer).copyCell,
+	"Y":      (*viewer).copyRow,
+}
+
+// update handles a key press and reports whether the viewer was closed.
+func (v *viewer) update(msg tea.KeyMsg) (tea.Cmd, bool) {
+	v.notice = ""
+	if v.searching {
+		v.editSearch(msg)
+		return nil, false
+	}
+
+	switch key := msg.String(); key {
+	case "q", "esc", "ctrl+c":
+		return nil, true
+	default:
+		action, ok := viewerKeys[key]
+		if !ok {
+			return nil, false
+		}
+		cmd := action(v)
+		v.scrollToCursor()
+		return cmd, false
+	}
+}
+
+func (v *viewer) moveRow(delta int) tea.Cmd {
+	last := len(v.rows) - 1
+	if v.text {
+		// text scrolls like a pager: the cursor is the top line
+		last = len(v.rows) - v.bodyHeight()
+	}
+	v.row = max(min(v.row+delta, last), 0)
+	return nil
+}
+
+func (v *viewer) moveCol(delta int) tea.Cmd {
+	if v.text {
+		last := max(v.widths[0]-v.width, 0)
+		v.left = max(min(v.left+delta*viewerTextScroll, last), 0)
+		return nil
+	}
+	v.col = max(min(v.col+delta, len(v.order)-1), 0)
+	return nil
+}
+
+// scrollToCursor moves the visible window so the cursor cell is on screen.
+func (v *viewer) scrollToCursor() {
+	if v.text {
+		v.top = v.row
+		return
+	}
+
+	body := v.bodyHeight()
+	switch {
+	case v.row < v.top:
+		v.top = v.row
+	case v.row >= v.top+body:
+		v.top = v.row - body + 1
+	}
+
+	if v.col < v.left {
+		v.left = v.col
+	}
+	for v.left < v.col && !v.fits(v.left, v.col) {
+		v.left++
+	}
+}
+
+// fits reports whether the columns from first to last, in display order,
+// fit on screen together.
+func (v *viewer) fits(first, last int) bool {
+	width := 0
+	for c := first; c <= last; c++ {
+		if c > first {
+			width += ansi.StringWidth(viewerColumnGap)
+		}
+		width += v.widths[v.order[c]]
+	}
+	return width <= v.width
+}
+
+func (v *viewer) hideColumn() tea.Cmd {
+	if v.text || len(v.order) == 1 {
+		v.notice = "cannot hide the last column"
+		return nil
+	}
+	v.order = slices.Delete(v.order, v.col, v.col+1)
+	v.col = min(v.col, len(v.order)-1)
+	return nil
+}
+
+// showAllColumns brings back hidden columns in their original order.
+func (v *viewer) showAllColumns() tea.Cmd {
+	current := v.order[v.col]
+	v.order = allColumns(len(v.widths))
+	v.col = current
+	return nil
+}
+
+// moveColumn swaps the cursor column with its neighbor.
+func (v *viewer) moveColumn(delta int) tea.Cmd {
+	next := v.col + delta
+	if v.text || next < 0 || next >= len(v.order) {
+		return nil
+	}
+	v.order[v.col], v.order[next] = v.order[next], v.order[v.col]
+	v.col = next
+	return nil
+}
+
+// sortByColumn sorts rows by the cursor column, ascending first and then
+// toggling direction. Numbers compare numerically.
+func (v *viewer) sortByColumn() tea.Cmd {
+	if v.text {
+		return nil
+	}
+	column := v.order[v.col]
+	if v.sorted && v.sortCol == column {
+		v.sortDesc = !v.sortDesc
+	} else {
+		v.sorted, v.sortCol, v.sortDesc = true, column, false
+	}
+
+	slices.SortStableFunc(v.rows, func(a, b []string) int {
+		if v.sortDesc {
+			return compareCells(b[column], a[column])
+		}
+		return compareCells(a[column], b[column])
+	})
+	v.row = 0
+	return nil
+}
+
+func compareCells(a, b string) int {
+	af, aErr := strconv.ParseFloat(a, 64)
+	bf, bErr := strconv.ParseFloat(b, 64)
+	if aErr == nil && bErr == nil {
+		return cmp.Compare(af, bf)
+	}
+	return strings.Compare(a, b)
+}
+
+func (v *viewer) startSearch() tea.Cmd {
+	v.searching = true
+	v.input = v.input[:0]
+	return nil
+}
+
+func (v *viewer) editSearch(msg tea.KeyMsg) {
+	switch msg.String() {
+	case "enter":
+		v.searching = false
+		if len(v.input) > 0 {
+			v.search = string(v.input)
+			v.findNext(1)
+			v.scrollToCursor()
+		}
+	case "esc", "ctrl+c":
+		v.searching = false
+	case "backspace":
+		if len(v.input) > 0 {
+			v.input = v.input[:len(v.input)-1]
+		}
+	default:
+		if press, ok := msg.(tea.KeyPressMsg); ok {
+			v.input = append(v.input, []rune(press.Key().Text)...)
+		}
+	}
+}
+
+// findNext moves the cursor to the next cell (or previous, with dir -1)
+// containing the search text, ignoring case and wrapping around.
+func (v *viewer) findNext(dir int) tea.Cmd {
+	if v.search == "" {
+		v.notice = "no search pattern, press / to search"
+		return nil
+	}
+
+	cols := len(v.order)
+	total := len(v.rows) * cols
+	pos := v.row*cols + v.col
+	for step := 1; step <= total; step++ {
+		next := ((pos+dir*step)%total + total) % total
+		row, col := next/cols, next%cols
+		if v.matches(v.rows[row][v.order[col]]) {
+			v.row, v.col = row, col
+			if v.text {
+				v.moveRow(0)
+			}
+			return nil
+		}
+	}
+	v.notice = "pattern not found: " + v.search
+	return nil
+}
+
+func (v *viewer) matches(cell string) bool {
+	return v.search != "" && strings.Contains(strings.ToLower(ansi.Strip(cell)), strings.ToLower(v.search))
+}
+
+func (v *viewer) copyCell() tea.Cmd {
+	if len(v.rows) == 0 {
+		return nil
+	}
+	v.notice = "copied cell"
+	if v.text {
+		v.notice = "copied line"
+	}
+	return tea.SetClipboard(ansi.Strip(v.rows[v.row][v.order[v.col]]))
+}
+
+// copyRow copies the visible cells of the cursor row, tab separated.
+func (v *viewer) copyRow() tea.Cmd {
+	if len(v.rows) == 0 {
+		return nil
+	}
+	cells := make([]string, len(v.order))
+	for i, column := range v.order {
+		cells[i] = ansi.Strip(v.rows[v.row][column])
+	}
+	v.notice = "copied row"
+	return tea.SetClipboard(strings.Join(cells, "\t"))
+}
+
+func (v *viewer) view() string {
+	var sb strings.Builder
+	if !v.text {
+		sb.WriteString(v.renderLine(-1))
+		sb.WriteByte('\n')
+	}
+	body := v.bodyHeight()
+	for i := v.top; i < v.top+body; i++ {
+		if i < len(v.rows) {
+			sb.WriteString(v.renderLine(i))
+		}
+		sb.WriteByte('\n')
+	}
+	status := ansi.Truncate(v.statusText(), max(v.width-2, 0), "…")
+	sb.WriteString(statusBarStyle.Width(v.width).Render(status))
+	return sb.String()
+}
+
+// renderLine draws row i, or the header when i is -1.
+func (v *viewer) renderLine(i int) string {
+	if v.text {
+		return ansi.Cut(sanitizeCell(v.rows[i][0]), v.left, v.left+v.width)
+	}
+
+	var sb strings.Builder
+	used := 0
+	for c := v.left; c < len(v.order); c++ {
+		if c > v.left {
+			sb.WriteString(viewerColumnGap)
+			used += ansi.StringWidth(viewerColumnGap)
+		}
+		width := min(v.widths[v.order[c]], v.width-used)
+		if width <= 0 {
+			break
+		}
+		sb.WriteString(v.renderCell(i, c, width))
+		used += width
+	}
+	return sb.String()
+}
+
+func (v *viewer) renderCell(i, c, width int) string {
+	column := v.order[c]
+	if i < 0 {
+		name := v.columns[column]
+		if v.sorted && v.sortCol == column && v.sortDesc {
+			name += " ↓"
+		} else if v.sorted && v.sortCol == column {
+			name += " ↑"
+		}
+		return viewerHeaderStyle.Render(fitCell(name, width))
+	}
+
+	cell := fitCell(sanitizeCell(v.rows[i][column]), width)
+	switch {
+	case i == v.row && c == v.col:
+		return viewerCursorStyle.Render(cell)
+	case v.matches(v.rows[i][column]):
+		return viewerMatchStyle.Render(cell)
+	default:
+		return cell
+	}
+}
+
+// fitCell truncates or pads s to exactly width cells.
+func fitCell(s string, width int) string {
+	s = ansi.Truncate(s, width, "…")
+	return s + strings.Repeat(" ", width-ansi.StringWidth(s))
+}
+
+func (v *viewer) statusText() string {
+	if v.searching {
+		return "/" + string(v.input)
+	}
+
+	var position string
+	if v.text {
+		position = fmt.Sprintf("line %d/%d", min(v.row+1, len(v.rows)), len(v.rows))
+	} else {
+		position = fmt.Sprintf("row %d/%d · %s (%d/%d)",
+			min(v.row+1, len(v.rows)), len(v.rows), v.columns[v.order[v.col]], v.col+1, len(v.order))
+		if hidden := len(v.columns) - len(v.order); hidden > 0 {
+			position += fmt.Sprintf(" · %d hidden", hidden)
+		}
+		if v.sorted {
+			position += " · sorted by " + v.columns[v.sortCol]
+		}
+	}
+
+	help := v.notice
+	if help == "" && v.text {
+		help = "/ search · y copy line · q quit"
+	} else if help == "" {
+		help = "/ search · s sort · x hide · u unhide · < > move · y/Y copy · q quit"
+	}
+	return position + " · " + help
+}
@@ -0,0 +1,153 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func press(v *viewer, keys ...string) (tea.Cmd, bool) {
+	var (
+		cmd    tea.Cmd
+		closed bool
+	)
+	for _, k := range keys {
+		var msg tea.KeyPressMsg
+		switch k {
+		case "down":
+			msg = tea.KeyPressMsg{Code: tea.KeyDown}
+		case "enter":
+			msg = tea.KeyPressMsg{Code: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyPressMsg{Code: tea.KeyEscape}
+		default:
+			r := []rune(k)[0]
+			msg = tea.KeyPressMsg{Code: r, Text: k}
+		}
+		cmd, closed = v.update(msg)
+	}
+	return cmd, closed
+}
+
+func testViewer() *viewer {
+	return newViewer(ViewerMsg{
+		Columns: []string{"id", "name", "note"},
+		Rows: [][]string{
+			{"10", "carol", "multi\nline"},
+			{"9", "alice", ""},
+			{"100", "bob", strings.Repeat("x", 60)},
+		},
+		Footer: "SELECT 3",
+	}, 40, 10)
+}
+
+func TestViewer_Layout(t *testing.T) {
+	v := testViewer()
+	assert.Equal(t, []int{3, 5, maxViewerColumnWidth}, v.widths)
+
+	lines := strings.Split(ansi.Strip(v.view()), "\n")
+	require.Len(t, lines, 10)
+	assert.Equal(t, "id  │ name  │ note", strings.TrimRight(lines[0], " "))
+	assert.Equal(t, "10  │ carol │ multi↵line", strings.TrimRight(lines[1], " "))
+	assert.Contains(t, lines[9], "row 1/3 · id (1/3)")
+
+	// the header stays in place while rows scroll
+	press(v, "G")
+	assert.Equal(t, 2, v.row)
+	assert.Equal(t, "id  │ name  │ note", strings.TrimRight(strings.Split(ansi.Strip(v.view()), "\n")[0], " "))
+}
+
+func TestViewer_Sort(t *testing.T) {
+	v := testViewer()
+
+	press(v, "s")
+	assert.Equal(t, []string{"9", "10", "100"}, columnValues(v, 0), "numbers sort numerically")
+	press(v, "s")
+	assert.Equal(t, []string{"100", "10", "9"}, columnValues(v, 0))
+
+	press(v, "l", "s")
+	assert.Equal(t, []string{"alice", "bob", "carol"}, columnValues(v, 1))
+	assert.Contains(t, v.statusText(), "sorted by name")
+}
+
+func columnValues(v *viewer, column int) []string {
+	values := make([]string, len(v.rows))
+	for i, row := range v.rows {
+		values[i] = row[column]
+	}
+	return values
+}
+
+func TestViewer_HideAndReorderColumns(t *testing.T) {
+	v := testViewer()
+
+	press(v, "x")
+	assert.Equal(t, []int{1, 2}, v.order)
+	assert.Contains(t, v.statusText(), "name (1/2) · 1 hidden")
+
+	press(v, ">")
+	assert.Equal(t, []int{2, 1}, v.order)
+	assert.Equal(t, 1, v.col)
+
+	press(v, "x", "x")
+	assert.Equal(t, []int{2}, v.order)
+	assert.Equal(t, "cannot hide the last column", v.notice)
+
+	press(v, "u")
+	assert.Equal(t, []int{0, 1, 2}, v.order)
+	assert.Equal(t, 2, v.col, "the cursor stays on the same column")
+}
+
+func TestViewer_Search(t *testing.T) {
+	v := testViewer()
+
+	press(v, "/", "B", "O", "enter")
+	assert.Equal(t, "BO", v.search)
+	assert.Equal(t, []int{2, 1}, []int{v.row, v.col})
+
+	press(v, "n")
+	assert.Equal(t, []int{2, 1}, []int{v.row, v.col}, "the only match wraps around to itself")
+
+	press(v, "/", "z", "enter")
+	assert.Equal(t, "pattern not found: z", v.notice)
+}
+
+func TestViewer_CopyAndClose(t *testing.T) {
+	v := testViewer()
+
+	cmd, closed := press(v, "down", "y")
+	assert.False(t, closed)
+	require.NotNil(t, cmd)
+	assert.Equal(t, "copied cell", v.notice)
+
+	_, closed = press(v, "Y")
+	assert.False(t, closed)
+	assert.Equal(t, "copied row", v.notice)
+
+	_, closed = press(v, "q")
+	assert.True(t, closed)
+}
+
+func TestViewer_Text(t *testing.T) {
+	lines := make([]string, 30)
+	for i := range lines {
+		lines[i] = strings.Repeat("abcdefghij", 3) + string(rune('A'+i%26))
+	}
+	v := newViewer(TextViewerMsg(strings.Join(lines, "\n")+"\n"), 20, 6)
+	require.Len(t, v.rows, 30)
+
+	out := strings.Split(v.view(), "\n")
+	assert.Equal(t, "abcdefghijabcdefghij", out[0], "text has no header")
+
+	press(v, "G")
+	assert.Equal(t, 25, v.top, "the last page fills the screen")
+
+	press(v, "l")
+	assert.Equal(t, "ijabcdefghijabcdefgh", strings.Split(v.view(), "\n")[0])
+	press(v, "l", "l")
+	assert.Equal(t, 11, v.left, "scrolling stops at the end of the longest line")
+}
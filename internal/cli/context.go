@@ -7,6 +7,7 @@ import (
 	"github.com/balaji01-4d/pgxcli/internal/config"
 	"github.com/balaji01-4d/pgxcli/internal/database"
 	"github.com/balaji01-4d/pgxcli/internal/logger"
+	"github.com/balaji01-4d/pgxcli/internal/sshtunnel"
 )
 
 // CliContext holds the dependencies for cli.
@@ -23,6 +24,10 @@ type CliContext struct { //revive:disable suggested context name would be misund
 	// Client is the database client used to interact with the Postgres database
 	Client *database.Client
 
+	// Tunnel is the SSH tunnel the database connections go through, nil
+	// without --ssh
+	Tunnel *sshtunnel.Tunnel
+
 	// App is the application layer that contains the business logic of pgxcli
 	// App orchestrates the execution of commands and interacts with the database client
 	// printer to perform operations and display results.
@@ -31,6 +31,10 @@ func getDatabaseFromEnv() string {
 	return firstEnv("PGXDATABASE", "PGDATABASE")
 }
 
+func getServiceFromEnv() string {
+	return firstEnv("PGXSERVICE", "PGSERVICE")
+}
+
 func getPortFromEnv() uint16 {
 	portStr := firstEnv("PGXPORT", "PGPORT")
 	if portStr == "" {
@@ -15,6 +15,7 @@ func clearEnv(t *testing.T) {
 		"PGXHOST", "PGHOST",
 		"PGXDATABASE", "PGDATABASE",
 		"PGXPORT", "PGPORT",
+		"PGXSERVICE", "PGSERVICE",
 	}
 	for _, v := range vars {
 		t.Setenv(v, "")
@@ -186,6 +187,35 @@ func Test_getDatabaseFromEnv(t *testing.T) {
 	}
 }
 
+func Test_getServiceFromEnv(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected string
+	}{
+		{
+			name:     "PGXSERVICE takes precedence",
+			env:      map[string]string{"PGXSERVICE": "svc1", "PGSERVICE": "svc2"},
+			expected: "svc1",
+		},
+		{
+			name:     "PGSERVICE used if PGXSERVICE is empty",
+			env:      map[string]string{"PGSERVICE": "svc2"},
+			expected: "svc2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			assert.Equal(t, tt.expected, getServiceFromEnv())
+		})
+	}
+}
+
 func Test_getPortFromEnv(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -46,6 +46,14 @@ func (f *neverPromptFlag) bind(cmd *cobra.Command) {
 	cmd.Flags().BoolVarP((*bool)(f), "no-password", "w", false, "never prompt for the password")
 }
 
+// savePasswordFlag refers to --save-password, which keeps the password of
+// a successful connection in the password store.
+type savePasswordFlag bool
+
+func (f *savePasswordFlag) bind(cmd *cobra.Command) {
+	cmd.Flags().BoolVar((*bool)(f), "save-password", false, "Save the password in the encrypted password store once connected")
+}
+
 // debugFlag use to debug application.
 type debugFlag bool
 
@@ -53,6 +61,70 @@ func (f *debugFlag) bind(cmd *cobra.Command) {
 	cmd.Flags().BoolVar((*bool)(f), "debug", false, "Enable debug mode for verbose logging.")
 }
 
+// globalHistoryFlag offers the history of every connection for recall,
+// overriding history_scope.
+type globalHistoryFlag bool
+
+func (f *globalHistoryFlag) bind(cmd *cobra.Command) {
+	cmd.Flags().BoolVar((*bool)(f), "global-history", false, "Browse the history of all connections, ignoring history_scope")
+}
+
+// profileFlag refers to --profile, naming a [connections.<name>] profile
+// like the @name argument does.
+type profileFlag string
+
+func (f *profileFlag) bind(cmd *cobra.Command) {
+	cmd.Flags().StringVar((*string)(f), "profile", "", "Connection profile from the [connections] config to connect with")
+}
+
+// serviceFlag refers to --service, naming an entry of the connection
+// service file (pg_service.conf).
+type serviceFlag string
+
+func (f *serviceFlag) bind(cmd *cobra.Command) {
+	cmd.Flags().StringVar((*string)(f), "service", "", "Service from pg_service.conf to connect with")
+}
+
+// sslFlags refers to --sslmode, --sslrootcert, --sslcert, --sslkey and
+// --sslpassword-file, the TLS settings of a field-based connection.
+type sslFlags struct {
+	mode         string
+	rootCert     string
+	cert         string
+	key          string
+	passwordFile string
+}
+
+func (f *sslFlags) bind(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.mode, "sslmode", "", "SSL mode: disable, allow, prefer, require, verify-ca or verify-full")
+	cmd.Flags().StringVar(&f.rootCert, "sslrootcert", "", "File of the CA certificates to verify the server against")
+	cmd.Flags().StringVar(&f.cert, "sslcert", "", "File of the client certificate")
+	cmd.Flags().StringVar(&f.key, "sslkey", "", "File of the client certificate key; its passphrase is asked for when encrypted")
+	// the passphrase is read from a file so it stays out of the process list
+	cmd.Flags().StringVar(&f.passwordFile, "sslpassword-file", "", "File holding the passphrase of an encrypted --sslkey")
+}
+
+// targetSessionAttrsFlag refers to --target-session-attrs, which picks the
+// host to settle on among the hosts of a multi-host connection.
+type targetSessionAttrsFlag string
+
+func (f *targetSessionAttrsFlag) bind(cmd *cobra.Command) {
+	cmd.Flags().StringVar((*string)(f), "target-session-attrs", "",
+		"Host to settle on among several: any, read-write, read-only, primary, standby or prefer-standby")
+}
+
+// sshFlags refers to --ssh and --ssh-identity, which tunnel the connection
+// through a bastion host.
+type sshFlags struct {
+	target   string
+	identity string
+}
+
+func (f *sshFlags) bind(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.target, "ssh", "", "Connect through an SSH tunnel to user@bastion[:port]")
+	cmd.Flags().StringVar(&f.identity, "ssh-identity", "", "Private key for the SSH tunnel, tried after the SSH agent")
+}
+
 // interactiveConnFlag launches a form for filling the database connection parameters.
 type interactiveConnFlag bool
 
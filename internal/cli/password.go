@@ -1,8 +1,12 @@
 package cli
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"os"
 
+	"github.com/balaji01-4d/pgxcli/internal/secrets"
 	"github.com/jackc/pgx/v5/pgconn"
 )
 
@@ -17,3 +21,118 @@ func shouldAskForPassword(err error, neverPrompt bool) bool {
 	}
 	return false
 }
+
+// passwordSources finds the password of a field-based connection when none
+// was given, and saves it when asked to.
+type passwordSources struct {
+	target      secrets.Target
+	command     string
+	storePath   string
+	neverPrompt bool
+
+	// store is unlocked once, when first needed.
+	store *secrets.Store
+}
+
+func newPasswordSources(params connectionParams, storeSetting string, neverPrompt bool) *passwordSources {
+	return &passwordSources{
+		target: secrets.Target{
+			Host:     params.host,
+			Port:     params.port,
+			Database: params.database,
+			User:     params.user,
+		},
+		command:     params.passwordCommand,
+		storePath:   secrets.StorePath(storeSetting),
+		neverPrompt: neverPrompt,
+	}
+}
+
+// lookup returns the password printed by password_command, if there is one.
+// An empty result leaves the password to PGPASSWORD and .pgpass, which pgx
+// reads itself, and then to the password store.
+func (s *passwordSources) lookup(ctx context.Context) (string, error) {
+	if s.command == "" {
+		return "", nil
+	}
+	return secrets.FromCommand(ctx, s.command, s.target)
+}
+
+// stored returns the password in the password store. The store is only
+// unlocked when the file exists and prompting is allowed, since unlocking
+// asks for the master passphrase.
+func (s *passwordSources) stored() (string, error) {
+	if s.neverPrompt || !s.storeExists() {
+		return "", nil
+	}
+	store, err := s.unlock()
+	if err != nil {
+		return "", err
+	}
+	password, _ := store.Lookup(s.target)
+	return password, nil
+}
+
+// save keeps password in the password store, creating the store, with a new
+// master passphrase, when it does not exist yet.
+func (s *passwordSources) save(password string) error {
+	if s.storePath == "" {
+		return errors.New("password_store is not set")
+	}
+	if password == "" {
+		return errors.New("no password was used to connect")
+	}
+	store, err := s.unlock()
+	if err != nil {
+		return err
+	}
+	store.Set(s.target, password)
+	return store.Save()
+}
+
+func (s *passwordSources) storeExists() bool {
+	if s.storePath == "" {
+		return false
+	}
+	_, err := os.Stat(s.storePath)
+	return err == nil
+}
+
+func (s *passwordSources) unlock() (*secrets.Store, error) {
+	if s.store != nil {
+		return s.store, nil
+	}
+	passphrase, err := s.masterPassphrase()
+	if err != nil {
+		return nil, err
+	}
+	store, err := secrets.OpenStore(s.storePath, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	s.store = store
+	return store, nil
+}
+
+// masterPassphrase asks for the passphrase of the password store, twice when
+// the store is about to be created.
+func (s *passwordSources) masterPassphrase() (string, error) {
+	if s.storeExists() {
+		return promptPassword("Enter master passphrase")
+	}
+	passphrase, err := promptPassword(fmt.Sprintf("Enter new master passphrase for %s", s.storePath))
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", errors.New("master passphrase must not be empty")
+	}
+	again, err := promptPassword("Enter it again")
+	if err != nil {
+		return "", err
+	}
+	if again != passphrase {
+		return "", errors.New("master passphrases do not match")
+	}
+	return passphrase, nil
+}
@@ -1,11 +1,22 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"path/filepath"
 	"testing"
 
+	"github.com/balaji01-4d/pgxcli/internal/config"
+	"github.com/balaji01-4d/pgxcli/internal/database"
+	"github.com/balaji01-4d/pgxcli/internal/logger"
+	"github.com/balaji01-4d/pgxcli/internal/secrets"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_shouldAskForPassword(t *testing.T) {
@@ -46,3 +57,165 @@ func Test_shouldAskForPassword(t *testing.T) {
 		})
 	}
 }
+
+func TestPasswordSources_Lookup(t *testing.T) {
+	params := connectionParams{host: "db.example.com", port: 5432, database: "app", user: "readonly"}
+	storePath := filepath.Join(t.TempDir(), "passwords.enc")
+	store, err := secrets.OpenStore(storePath, "correct horse")
+	require.NoError(t, err)
+	store.Set(secrets.Target{Host: "db.example.com", Port: 5432, Database: "app", User: "readonly"}, "from store")
+	require.NoError(t, store.Save())
+
+	t.Run("command comes first", func(t *testing.T) {
+		withCommand := params
+		withCommand.passwordCommand = `sh -c 'echo "from command for $PGXCLI_USER"'`
+		sources := newPasswordSources(withCommand, storePath, false)
+		got, err := sources.lookup(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "from command for readonly", got)
+	})
+
+	t.Run("store is left to a refused password", func(t *testing.T) {
+		sources := newPasswordSources(params, storePath, false)
+		got, err := sources.lookup(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, got)
+		assert.Nil(t, sources.store)
+	})
+}
+
+func TestPasswordSources_Stored(t *testing.T) {
+	params := connectionParams{host: "db.example.com", port: 5432, database: "app", user: "readonly"}
+	storePath := filepath.Join(t.TempDir(), "passwords.enc")
+	store, err := secrets.OpenStore(storePath, "correct horse")
+	require.NoError(t, err)
+	store.Set(secrets.Target{Host: "db.example.com", Port: 5432, Database: "app", User: "readonly"}, "from store")
+	require.NoError(t, store.Save())
+
+	t.Run("unlocked store", func(t *testing.T) {
+		sources := newPasswordSources(params, storePath, false)
+		sources.store = store
+		got, err := sources.stored()
+		require.NoError(t, err)
+		assert.Equal(t, "from store", got)
+	})
+
+	t.Run("store is not unlocked without prompting", func(t *testing.T) {
+		sources := newPasswordSources(params, storePath, true)
+		got, err := sources.stored()
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("no store", func(t *testing.T) {
+		sources := newPasswordSources(params, filepath.Join(t.TempDir(), "missing.enc"), false)
+		got, err := sources.stored()
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+}
+
+// startPasswordServer starts a fake PostgreSQL server that only lets in
+// clients sending password, and answers their pings.
+func startPasswordServer(t *testing.T, password string) uint16 {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go servePassword(conn, password)
+		}
+	}()
+	return uint16(ln.Addr().(*net.TCPAddr).Port)
+}
+
+func servePassword(conn net.Conn, password string) {
+	defer conn.Close()
+	backend := pgproto3.NewBackend(conn, conn)
+	if _, err := backend.ReceiveStartupMessage(); err != nil {
+		return
+	}
+
+	backend.Send(&pgproto3.AuthenticationCleartextPassword{})
+	if err := backend.Flush(); err != nil {
+		return
+	}
+	if err := backend.SetAuthType(pgproto3.AuthTypeCleartextPassword); err != nil {
+		return
+	}
+	msg, err := backend.Receive()
+	if err != nil {
+		return
+	}
+	if sent, ok := msg.(*pgproto3.PasswordMessage); !ok || sent.Password != password {
+		backend.Send(&pgproto3.ErrorResponse{Severity: "FATAL", Code: "28P01", Message: "password authentication failed"})
+		_ = backend.Flush()
+		return
+	}
+
+	backend.Send(&pgproto3.AuthenticationOk{})
+	backend.Send(&pgproto3.BackendKeyData{ProcessID: 42, SecretKey: []byte{0, 0, 0, 1}})
+	backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+	for backend.Flush() == nil {
+		msg, err := backend.Receive()
+		if err != nil {
+			return
+		}
+		if _, ok := msg.(*pgproto3.Query); !ok {
+			return
+		}
+		backend.Send(&pgproto3.EmptyQueryResponse{})
+		backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+	}
+}
+
+func TestConnectWithFields_PasswordStore(t *testing.T) {
+	port := startPasswordServer(t, "s3cret")
+	params := connectionParams{host: "127.0.0.1", port: port, database: "app", user: "app", sslmode: "disable"}
+
+	storePath := filepath.Join(t.TempDir(), "passwords.enc")
+	store, err := secrets.OpenStore(storePath, "correct horse")
+	require.NoError(t, err)
+	store.Set(secrets.Target{Host: "127.0.0.1", Port: port, Database: "app", User: "app"}, "s3cret")
+	require.NoError(t, store.Save())
+
+	var prompts []string
+	promptPassword = func(prompt string) (string, error) {
+		prompts = append(prompts, prompt)
+		return "correct horse", nil
+	}
+	t.Cleanup(func() { promptPassword = readPassword })
+	t.Setenv("PGPASSFILE", filepath.Join(t.TempDir(), "missing"))
+
+	connect := func(t *testing.T) error {
+		prompts = nil
+		cliCtx := &CliContext{
+			config: &config.Config{Main: config.MainConfig{PasswordStore: storePath}},
+			Logger: logger.NopLogger(),
+			Client: database.New(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		}
+		err := connectWithFields(context.Background(), cliCtx, params, false, false)
+		if err == nil {
+			require.NoError(t, cliCtx.Client.Close(context.Background()))
+		}
+		return err
+	}
+
+	t.Run("environment password asks for nothing", func(t *testing.T) {
+		t.Setenv("PGPASSWORD", "s3cret")
+		require.NoError(t, connect(t))
+		assert.Empty(t, prompts, "the password store is not unlocked")
+	})
+
+	t.Run("refused password unlocks the store", func(t *testing.T) {
+		t.Setenv("PGPASSWORD", "wrong")
+		require.NoError(t, connect(t))
+		assert.Equal(t, []string{"Enter master passphrase"}, prompts)
+	})
+}
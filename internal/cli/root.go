@@ -16,6 +16,8 @@ import (
 	"github.com/balaji01-4d/pgxcli/internal/config"
 	"github.com/balaji01-4d/pgxcli/internal/database"
 	"github.com/balaji01-4d/pgxcli/internal/logger"
+	"github.com/balaji01-4d/pgxcli/internal/parser"
+	"github.com/balaji01-4d/pgxcli/internal/sshtunnel"
 	"github.com/balaji01-4d/pgxcli/internal/ui"
 	"github.com/spf13/cobra"
 )
@@ -35,14 +37,21 @@ func NewRootCmd(ctx context.Context, cliCtx *CliContext) *cobra.Command {
 		usernameFlag        usernameFlag
 		neverPromptFlag     neverPromptFlag
 		forcePromptFlag     forcePromptFlag
+		savePasswordFlag    savePasswordFlag
 		interactiveConnFlag interactiveConnFlag
+		globalHistoryFlag   globalHistoryFlag
+		profileFlag         profileFlag
+		serviceFlag         serviceFlag
+		sslFlags            sslFlags
+		targetSessionAttrs  targetSessionAttrsFlag
+		sshFlags            sshFlags
 	)
 
 	rootCmd := &cobra.Command{
-		Use:           "pgxcli [DBNAME] [USERNAME]",
+		Use:           "pgxcli [@PROFILE] [DBNAME] [USERNAME]",
 		Short:         "Interactive PostgreSQL command-line client for querying and managing databases.",
 		Version:       version,
-		Args:          cobra.MaximumNArgs(2), // Database name and username are optional example: pgxcli mydb myuser
+		Args:          maxArgsAfterProfile(2), // Database name and username are optional example: pgxcli mydb myuser
 		SilenceUsage:  true,
 		SilenceErrors: true,
 
@@ -54,6 +63,10 @@ func NewRootCmd(ctx context.Context, cliCtx *CliContext) *cobra.Command {
 			params, err := resolveConnectionParams(
 				cmd,
 				args,
+				cliCtx.config,
+				string(profileFlag),
+				string(serviceFlag),
+				sslFlags,
 				bool(interactiveConnFlag),
 				string(dbNameFlag),
 				string(usernameFlag),
@@ -63,13 +76,24 @@ func NewRootCmd(ctx context.Context, cliCtx *CliContext) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			params.targetSessionAttrs = string(targetSessionAttrs)
+			profile, _ := cliCtx.config.Profile(params.profile)
+			params.applySSH(sshFlags, profile)
+			params.passwordCommand = firstNonEmpty(profile.PasswordCommand, cliCtx.config.Main.PasswordCommand)
+			params.savePassword = bool(savePasswordFlag)
 			if err := connectClient(ctx, cliCtx, params, bool(neverPromptFlag), bool(forcePromptFlag)); err != nil {
 				return err
 			}
 			if err := ensureConnected(cliCtx); err != nil {
 				return err
 			}
-			return initApplication(cliCtx)
+			if globalHistoryFlag {
+				cliCtx.config.Main.HistoryScope = config.HistoryScopeGlobal
+			}
+			if profile.Prompt != "" {
+				cliCtx.config.Main.Prompt = profile.Prompt
+			}
+			return initApplication(cliCtx, params.profile)
 		},
 
 		RunE: func(_ *cobra.Command, _ []string) error {
@@ -84,22 +108,7 @@ func NewRootCmd(ctx context.Context, cliCtx *CliContext) *cobra.Command {
 		},
 
 		PersistentPostRunE: func(_ *cobra.Command, _ []string) error {
-			if cliCtx.Logger != nil {
-				if err := cliCtx.Logger.Close(); err != nil {
-					return err
-				}
-			}
-			if cliCtx.Client != nil {
-				if err := cliCtx.Client.Close(ctx); err != nil {
-					return err
-				}
-			}
-			if cliCtx.App != nil {
-				if err := cliCtx.App.Close(); err != nil {
-					return err
-				}
-			}
-			return nil
+			return releaseRuntimeDependencies(ctx, cliCtx)
 		},
 	}
 
@@ -115,9 +124,17 @@ func NewRootCmd(ctx context.Context, cliCtx *CliContext) *cobra.Command {
 	usernameFlag.bind(rootCmd)
 	neverPromptFlag.bind(rootCmd)
 	forcePromptFlag.bind(rootCmd)
+	savePasswordFlag.bind(rootCmd)
 	interactiveConnFlag.bind(rootCmd)
+	globalHistoryFlag.bind(rootCmd)
+	profileFlag.bind(rootCmd)
+	serviceFlag.bind(rootCmd)
+	sslFlags.bind(rootCmd)
+	targetSessionAttrs.bind(rootCmd)
+	sshFlags.bind(rootCmd)
 
 	rootCmd.MarkFlagsMutuallyExclusive("no-password", "password")
+	rootCmd.MarkFlagsMutuallyExclusive("no-password", "save-password")
 
 	return rootCmd
 }
@@ -128,6 +145,29 @@ type connectionParams struct {
 	host     string
 	port     uint16
 	password string
+	service  string
+	options  string
+
+	sslmode     string
+	sslrootcert string
+	sslcert     string
+	sslkey      string
+	// sslpasswordFile holds the passphrase of an encrypted sslkey.
+	sslpasswordFile string
+
+	targetSessionAttrs string
+
+	// ssh is the user@host[:port] bastion to tunnel through, if any.
+	ssh         string
+	sshIdentity string
+
+	// passwordCommand prints the password when none was given.
+	passwordCommand string
+	// savePassword keeps the password in the password store once connected.
+	savePassword bool
+
+	// profile is the name of the connection profile the params came from, if any.
+	profile string
 }
 
 // loadRuntimeDependencies loads configuration, initializes logger, and sets up pager.
@@ -142,7 +182,11 @@ func loadRuntimeDependencies(cliCtx *CliContext, debug bool) error {
 		return err
 	}
 
-	initializedLogger, err := logger.InitLogger(debug, cfg.Main.LogFile)
+	redactor, err := parser.NewRedactor(cfg.Main.RedactPatterns)
+	if err != nil {
+		return err
+	}
+	initializedLogger, err := logger.InitLogger(debug, cfg.Main.LogFile, redactor.Redact)
 	if err != nil {
 		return err
 	}
@@ -151,50 +195,185 @@ func loadRuntimeDependencies(cliCtx *CliContext, debug bool) error {
 	return nil
 }
 
+// releaseRuntimeDependencies closes the logger, the database connection,
+// the SSH tunnel and the app, in that order.
+func releaseRuntimeDependencies(ctx context.Context, cliCtx *CliContext) error {
+	if cliCtx.Logger != nil {
+		if err := cliCtx.Logger.Close(); err != nil {
+			return err
+		}
+	}
+	if cliCtx.Client != nil {
+		if err := cliCtx.Client.Close(ctx); err != nil {
+			return err
+		}
+	}
+	if cliCtx.Tunnel != nil {
+		if err := cliCtx.Tunnel.Close(); err != nil {
+			return err
+		}
+	}
+	if cliCtx.App != nil {
+		if err := cliCtx.App.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maxArgsAfterProfile accepts at most n positional arguments besides a
+// leading @profile.
+func maxArgsAfterProfile(n int) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if len(args) > 0 && strings.HasPrefix(args[0], "@") {
+			args = args[1:]
+		}
+		return cobra.MaximumNArgs(n)(cmd, args)
+	}
+}
+
+// splitProfileArg takes the profile name off a leading @name argument,
+// falling back to the --profile flag. Naming a profile both ways is an error.
+func splitProfileArg(args []string, profileOpt string) ([]string, string, error) {
+	if len(args) == 0 || !strings.HasPrefix(args[0], "@") {
+		return args, strings.ToLower(profileOpt), nil
+	}
+	if profileOpt != "" {
+		return nil, "", fmt.Errorf("profile given as both %s and --profile %s", args[0], profileOpt)
+	}
+	return args[1:], strings.ToLower(strings.TrimPrefix(args[0], "@")), nil
+}
+
+// lookupProfile returns the profile called name, or the zero profile when
+// name is empty.
+func lookupProfile(cfg *config.Config, name string) (config.ConnectionProfile, error) {
+	if name == "" {
+		return config.ConnectionProfile{}, nil
+	}
+	profile, ok := cfg.Profile(name)
+	if !ok {
+		return config.ConnectionProfile{}, fmt.Errorf("unknown connection profile %q", name)
+	}
+	return profile, nil
+}
+
 func resolveConnectionParams(
 	cmd *cobra.Command,
 	args []string,
+	cfg *config.Config,
+	profileOpt string,
+	serviceOpt string,
+	ssl sslFlags,
 	interactive bool,
 	dbnameOpt string,
 	userOpt string,
 	hostOpt string,
 	portOpt uint16,
 ) (connectionParams, error) {
+	args, profileName, err := splitProfileArg(args, profileOpt)
+	if err != nil {
+		return connectionParams{}, err
+	}
+	profile, err := lookupProfile(cfg, profileName)
+	if err != nil {
+		return connectionParams{}, err
+	}
+
 	argDB, argUser := parsePositionalDBAndUser(args)
 	if interactive {
-		return resolveInteractiveConnectionParams(cmd, argDB, argUser, dbnameOpt, userOpt, hostOpt, portOpt)
+		form := connectionForm{cfg: cfg, profileName: profileName, profile: profile, service: serviceOpt, ssl: ssl}
+		return form.resolve(cmd, argDB, argUser, dbnameOpt, userOpt, hostOpt, portOpt)
 	}
 
+	// A profile fills in what the flags and arguments leave out.
 	database, user := resolveDBAndUser(dbnameOpt, userOpt, argDB, argUser)
+	database = firstNonEmpty(database, profile.DBName)
+	user = firstNonEmpty(user, profile.User)
+	hostOpt, portOpt = profileHostAndPort(cmd, profile, hostOpt, portOpt)
 
-	if user == "" {
-		user = getDefaultUser()
+	params := connectionParams{
+		database: database,
+		user:     user,
+		host:     hostOpt,
+		port:     portOpt,
+		service:  firstNonEmpty(serviceOpt, getServiceFromEnv()),
+		options:  profile.Options,
+		profile:  profileName,
+	}
+	params.applySSL(ssl, profile)
+	if params.service != "" {
+		// The service fills in what is still missing, so the defaults,
+		// which would override it, are left to the connection itself.
+		if !cmd.Flags().Changed("port") && profile.Port == 0 {
+			params.port = 0
+		}
+		return params, nil
+	}
+	return withDefaults(params), nil
+}
+
+// withDefaults fills in the user, database, host and port that nothing
+// else gave from the environment.
+func withDefaults(params connectionParams) connectionParams {
+	if params.user == "" {
+		params.user = getDefaultUser()
 	}
 
-	if database == "" {
-		database = getDatabaseFromEnv()
-		if database == "" {
-			database = user
+	if params.database == "" {
+		params.database = getDatabaseFromEnv()
+		if params.database == "" {
+			params.database = params.user
 		}
 	}
 
-	if hostOpt == "" {
-		hostOpt = getHostFromEnv()
+	if params.host == "" {
+		params.host = getHostFromEnv()
+	}
+
+	if params.port == 0 {
+		params.port = getPortFromEnv()
 	}
+	return params
+}
+
+// applySSL sets the TLS settings from the flags, falling back to profile.
+func (p *connectionParams) applySSL(flags sslFlags, profile config.ConnectionProfile) {
+	p.sslmode = firstNonEmpty(flags.mode, profile.SSLMode)
+	p.sslrootcert = firstNonEmpty(flags.rootCert, profile.SSLRootCert)
+	p.sslcert = firstNonEmpty(flags.cert, profile.SSLCert)
+	p.sslkey = firstNonEmpty(flags.key, profile.SSLKey)
+	p.sslpasswordFile = firstNonEmpty(flags.passwordFile, profile.SSLPasswordFile)
+}
+
+// applySSH sets the SSH tunnel settings from the flags, falling back to profile.
+func (p *connectionParams) applySSH(flags sshFlags, profile config.ConnectionProfile) {
+	p.ssh = firstNonEmpty(flags.target, profile.SSH)
+	p.sshIdentity = firstNonEmpty(flags.identity, profile.SSHIdentity)
+}
 
-	if portOpt == 0 {
-		portOpt = getPortFromEnv()
+// profileHostAndPort returns the host and port of profile where the
+// command line does not give them.
+func profileHostAndPort(cmd *cobra.Command, profile config.ConnectionProfile, hostOpt string, portOpt uint16) (string, uint16) {
+	hostOpt = firstNonEmpty(hostOpt, profile.Host)
+	if !cmd.Flags().Changed("port") && profile.Port != 0 {
+		portOpt = profile.Port
 	}
+	return hostOpt, portOpt
+}
 
-	return connectionParams{
-		database: database,
-		user:     user,
-		host:     hostOpt,
-		port:     portOpt,
-	}, nil
+// connectionForm resolves connection params through the -i form.
+type connectionForm struct {
+	cfg *config.Config
+	// profileName and profile are set when a profile was named on the
+	// command line; otherwise the form offers a picker.
+	profileName string
+	profile     config.ConnectionProfile
+	// service is the --service to connect through, if any.
+	service string
+	ssl     sslFlags
 }
 
-func resolveInteractiveConnectionParams(
+func (f connectionForm) resolve(
 	cmd *cobra.Command,
 	argDB string,
 	argUser string,
@@ -204,30 +383,45 @@ func resolveInteractiveConnectionParams(
 	portOpt uint16,
 ) (connectionParams, error) {
 	// In interactive mode, flags / args are used as defaults.
-	// Priority is flag, arg, env, default.
-	formDB := firstNonEmpty(dbnameOpt, argDB)
-	formUser := firstNonEmpty(userOpt, argUser, getDefaultUser())
+	// Priority is flag, arg, profile, env, default.
+	formDB := firstNonEmpty(dbnameOpt, argDB, f.profile.DBName)
+	formUser := firstNonEmpty(userOpt, argUser, f.profile.User, getDefaultUser())
 
-	var formHost string
-	var formPort string
+	formHost := f.profile.Host
 	if cmd.Flags().Changed("host") {
 		formHost = hostOpt
 	}
-	if cmd.Flags().Changed("port") {
+	var formPort string
+	switch {
+	case cmd.Flags().Changed("port"):
 		formPort = strconv.FormatUint(uint64(portOpt), 10)
+	case f.profile.Port != 0:
+		formPort = strconv.FormatUint(uint64(f.profile.Port), 10)
 	}
 
-	connValues, err := ui.RunConnectionForm(formDB, formUser, formHost, formPort)
+	var profiles []ui.Profile
+	if f.profileName == "" {
+		profiles = formProfiles(f.cfg)
+	}
+	connValues, err := ui.RunConnectionForm(formDB, formUser, formHost, formPort, profiles)
 	if err != nil {
 		return connectionParams{}, err
 	}
+	if connValues.Profile != "" {
+		f.profileName = connValues.Profile
+		f.profile, _ = f.cfg.Profile(connValues.Profile)
+	}
 
 	params := connectionParams{
 		database: connValues.Database,
 		user:     connValues.Username,
 		host:     connValues.Host,
 		password: connValues.Password,
+		service:  firstNonEmpty(f.service, getServiceFromEnv()),
+		options:  f.profile.Options,
+		profile:  f.profileName,
 	}
+	params.applySSL(f.ssl, f.profile)
 	if connValues.Port != "" {
 		// Ignoring error since the form validation ensures this is a valid port.
 		params.port, err = mustParsePort(connValues.Port)
@@ -236,9 +430,49 @@ func resolveInteractiveConnectionParams(
 		}
 	}
 
+	if connValues.SaveAs != "" {
+		f.save(connValues.SaveAs, params)
+	}
 	return params, nil
 }
 
+// save stores params as a new profile. The password is never saved, and a
+// failure only warns, since the connection itself can still go ahead.
+func (f connectionForm) save(name string, params connectionParams) {
+	err := f.cfg.SaveProfile(name, config.ConnectionProfile{
+		Host:        params.host,
+		Port:        params.port,
+		DBName:      params.database,
+		User:        params.user,
+		SSLMode:     params.sslmode,
+		SSLRootCert: params.sslrootcert,
+		SSLCert:     params.sslcert,
+		SSLKey:      params.sslkey,
+		Options:     params.options,
+	})
+	if err != nil {
+		_ = renderer.Error(fmt.Errorf("could not save profile: %w", err), os.Stderr)
+	}
+}
+
+// formProfiles lists the configured profiles for the picker of the -i form.
+func formProfiles(cfg *config.Config) []ui.Profile {
+	var profiles []ui.Profile
+	for _, name := range cfg.ProfileNames() {
+		profile, _ := cfg.Profile(name)
+		values := ui.ConnectionValues{
+			Database: profile.DBName,
+			Username: profile.User,
+			Host:     profile.Host,
+		}
+		if profile.Port != 0 {
+			values.Port = strconv.FormatUint(uint64(profile.Port), 10)
+		}
+		profiles = append(profiles, ui.Profile{Name: name, Values: values})
+	}
+	return profiles
+}
+
 func connectClient(
 	ctx context.Context,
 	cliCtx *CliContext,
@@ -248,8 +482,18 @@ func connectClient(
 ) error {
 	cliCtx.Client = database.New(cliCtx.Logger.Logger)
 
+	if params.ssh != "" {
+		if err := openTunnel(ctx, cliCtx, params, neverPrompt); err != nil {
+			return err
+		}
+	}
+
 	if strings.Contains(params.database, "://") || strings.Contains(params.database, "=") {
-		return connectWithConnString(ctx, cliCtx, params.database)
+		connString := params.database
+		if params.targetSessionAttrs != "" {
+			connString = database.AddConnSetting(connString, "target_session_attrs", params.targetSessionAttrs)
+		}
+		return connectWithConnString(ctx, cliCtx, connString)
 	}
 
 	return connectWithFields(ctx, cliCtx, params, neverPrompt, forcePrompt)
@@ -261,6 +505,7 @@ func connectWithConnString(ctx context.Context, cliCtx *CliContext, connString s
 		cliCtx.Logger.Error("Invalid Connection string", "error", err)
 		return err
 	}
+	useTunnel(cliCtx, connector)
 
 	cliCtx.Logger.Debug("Attempting database connection using connection string")
 	if err := cliCtx.Client.Connect(ctx, connector); err != nil {
@@ -278,42 +523,55 @@ func connectWithFields(
 	neverPrompt bool,
 	forcePrompt bool,
 ) error {
-	password := params.password
-
 	cliCtx.Logger.Debug("using field-based connection",
+		"service", params.service,
 		"host", params.host,
 		"port", params.port,
 		"database", params.database,
 		"user", params.user,
 	)
 
-	if neverPrompt && password == "" {
-		password = getPasswordFromEnv()
+	sources := newPasswordSources(params, cliCtx.config.Main.PasswordStore, neverPrompt)
+	password, err := firstPassword(ctx, params.password, sources, neverPrompt, forcePrompt)
+	if err != nil {
+		cliCtx.Logger.Error("Failed to look up password", "error", err)
+		return err
 	}
 
-	if forcePrompt && password == "" {
-		pwd, err := promptPassword("Enter password")
-		if err != nil {
-			return err
-		}
-		password = pwd
+	sslPassword, err := readSSLPassword(params.sslpasswordFile)
+	if err != nil {
+		return err
 	}
 
-	connector, err := database.NewPGConnectorFromFields(
-		params.host,
-		params.database,
-		params.user,
-		password,
-		params.port,
-	)
+	connector, err := database.NewPGConnectorFromFields(database.ConnFields{
+		Service:            params.service,
+		Host:               params.host,
+		Port:               params.port,
+		Database:           params.database,
+		User:               params.user,
+		Password:           password,
+		Options:            params.options,
+		SSLMode:            params.sslmode,
+		SSLRootCert:        params.sslrootcert,
+		SSLCert:            params.sslcert,
+		SSLKey:             params.sslkey,
+		SSLPassword:        sslPassword,
+		GetSSLPassword:     sslPasswordPrompt(neverPrompt),
+		TargetSessionAttrs: params.targetSessionAttrs,
+	})
 	if err != nil {
 		cliCtx.Logger.Error("Failed to create connector", "error", err)
 		return err
 	}
+	useTunnel(cliCtx, connector)
 
 	cliCtx.Logger.Debug("Attempting database connection")
 	connErr := cliCtx.Client.Connect(ctx, connector)
+	if shouldAskForPassword(connErr, neverPrompt) {
+		password, connErr = retryStoredPassword(ctx, cliCtx, connector, sources, password, connErr)
+	}
 	if connErr == nil {
+		savePassword(cliCtx, sources, params.savePassword, password)
 		return nil
 	}
 
@@ -340,10 +598,127 @@ func connectWithFields(
 		cliCtx.Logger.Error("Connection retry failed", "error", connRetryErr)
 		return connRetryErr
 	}
+	savePassword(cliCtx, sources, params.savePassword, pwd)
+
+	return nil
+}
+
+// firstPassword returns the password of the first connection attempt: the
+// one given, or from the environment with -w, or asked for with -W, or else
+// printed by password_command.
+func firstPassword(ctx context.Context, password string, sources *passwordSources, neverPrompt, forcePrompt bool) (string, error) {
+	if neverPrompt && password == "" {
+		password = getPasswordFromEnv()
+	}
+	if forcePrompt && password == "" {
+		pwd, err := promptPassword("Enter password")
+		if err != nil {
+			return "", err
+		}
+		password = pwd
+	}
+	if password != "" {
+		return password, nil
+	}
+	return sources.lookup(ctx)
+}
+
+// retryStoredPassword connects again with the password in the password
+// store once the password tried was refused. The store is unlocked only
+// then, so a password from the environment or .pgpass, or a server that
+// needs none, never asks for the master passphrase. It returns the password
+// of the last attempt and its error, connErr when there was no other attempt.
+func retryStoredPassword(
+	ctx context.Context,
+	cliCtx *CliContext,
+	connector database.Connector,
+	sources *passwordSources,
+	tried string,
+	connErr error,
+) (string, error) {
+	stored, err := sources.stored()
+	if err != nil {
+		cliCtx.Logger.Error("Failed to look up password", "error", err)
+		return tried, err
+	}
+	if stored == "" || stored == tried {
+		return tried, connErr
+	}
+
+	cliCtx.Logger.Debug("Retrying connection with the stored password")
+	connector.UpdatePassword(stored)
+	return stored, cliCtx.Client.Connect(ctx, connector)
+}
+
+// savePassword keeps the password of a successful connection in the password
+// store when --save-password was given. Failing to do so is reported without
+// ending the session.
+func savePassword(cliCtx *CliContext, sources *passwordSources, requested bool, password string) {
+	if !requested {
+		return
+	}
+	if err := sources.save(password); err != nil {
+		cliCtx.Logger.Error("Failed to save password", "error", err)
+		_ = renderer.Error(fmt.Errorf("could not save password: %w", err), os.Stderr)
+	}
+}
+
+// openTunnel opens the SSH tunnel to the bastion of params, which every
+// connection of the session then goes through.
+func openTunnel(ctx context.Context, cliCtx *CliContext, params connectionParams, neverPrompt bool) error {
+	cliCtx.Logger.Debug("opening ssh tunnel", "target", params.ssh)
+	cfg := sshtunnel.Config{Target: params.ssh, IdentityFile: params.sshIdentity}
+	if !neverPrompt {
+		cfg.Passphrase = func(file string) (string, error) {
+			return promptPassword(fmt.Sprintf("Enter passphrase for key %s", file))
+		}
+	}
 
+	tunnel, err := sshtunnel.Open(ctx, cfg)
+	if err != nil {
+		cliCtx.Logger.Error("Failed to open ssh tunnel", "error", err)
+		return err
+	}
+	cliCtx.Tunnel = tunnel
 	return nil
 }
 
+// useTunnel makes connector dial through the SSH tunnel, when there is one.
+func useTunnel(cliCtx *CliContext, connector database.Connector) {
+	if cliCtx.Tunnel != nil {
+		connector.SetDialFunc(cliCtx.Tunnel.DialContext)
+	}
+}
+
+// readSSLPassword returns the first line of the --sslpassword-file, or an
+// empty passphrase when there is no such file.
+func readSSLPassword(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("reading sslpassword file: %w", err)
+	}
+	line, _, _ := strings.Cut(string(data), "\n")
+	return strings.TrimSuffix(line, "\r"), nil
+}
+
+// sslPasswordPrompt asks for the passphrase of an encrypted client key,
+// unless prompting is turned off.
+func sslPasswordPrompt(neverPrompt bool) func(context.Context) string {
+	if neverPrompt {
+		return nil
+	}
+	return func(context.Context) string {
+		pwd, err := promptPassword("Enter PEM pass phrase")
+		if err != nil {
+			return ""
+		}
+		return pwd
+	}
+}
+
 func ensureConnected(cliCtx *CliContext) error {
 	if cliCtx.Client.IsConnected() {
 		return nil
@@ -356,9 +731,9 @@ func ensureConnected(cliCtx *CliContext) error {
 
 // initApplication Initializes the app,
 // which includes setting up the logger, config and autocompleter with PostgreSQL keywords.
-func initApplication(cliCtx *CliContext) error {
+func initApplication(cliCtx *CliContext, profile string) error {
 	completer := completer.New(cliCtx.Logger.Logger)
-	pgxCLI, err := app.New(cliCtx.config, cliCtx.Printer, cliCtx.Logger.Logger, completer)
+	pgxCLI, err := app.New(cliCtx.config, cliCtx.Printer, cliCtx.Logger.Logger, completer, profile)
 	if err != nil {
 		cliCtx.Logger.Error("Failed to initialize app", "error", err)
 		return err
@@ -401,7 +776,11 @@ func parsePositionalDBAndUser(args []string) (string, string) {
 	return db, user
 }
 
-func promptPassword(s string) (string, error) {
+// promptPassword asks for a secret on the terminal. Tests replace it to see
+// whether anything was asked.
+var promptPassword = readPassword
+
+func readPassword(s string) (string, error) {
 	fmt.Printf("%s: ", s)
 	fd := int(os.Stdin.Fd())
 	oldState, err := term.GetState(fd)
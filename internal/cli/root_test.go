@@ -1,9 +1,14 @@
 package cli
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/balaji01-4d/pgxcli/internal/config"
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type dbAndUserTestCase struct {
@@ -108,3 +113,96 @@ func TestResolveDBAndUser(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitProfileArg(t *testing.T) {
+	testcases := []struct {
+		name        string
+		args        []string
+		profileOpt  string
+		wantArgs    []string
+		wantProfile string
+		wantErr     string
+	}{
+		{name: "no profile", args: []string{"db", "user"}, wantArgs: []string{"db", "user"}},
+		{name: "at argument", args: []string{"@Prod", "db"}, wantArgs: []string{"db"}, wantProfile: "prod"},
+		{name: "flag", args: []string{"db"}, profileOpt: "prod", wantArgs: []string{"db"}, wantProfile: "prod"},
+		{name: "both", args: []string{"@prod"}, profileOpt: "dev", wantErr: "profile given as both @prod and --profile dev"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			args, profile, err := splitProfileArg(tc.args, tc.profileOpt)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantArgs, args)
+			assert.Equal(t, tc.wantProfile, profile)
+		})
+	}
+}
+
+func TestLookupProfile(t *testing.T) {
+	cfg := &config.Config{Connections: map[string]config.ConnectionProfile{
+		"prod": {Host: "db.example.com", DBName: "app"},
+	}}
+
+	profile, err := lookupProfile(cfg, "")
+	assert.NoError(t, err)
+	assert.Equal(t, config.ConnectionProfile{}, profile)
+
+	profile, err = lookupProfile(cfg, "prod")
+	assert.NoError(t, err)
+	assert.Equal(t, "db.example.com", profile.Host)
+
+	_, err = lookupProfile(cfg, "staging")
+	assert.EqualError(t, err, `unknown connection profile "staging"`)
+}
+
+func TestResolveConnectionParams_Service(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("PGHOST", "envhost")
+
+	cmd := &cobra.Command{}
+	var port portFlag
+	port.bind(cmd)
+
+	params, err := resolveConnectionParams(cmd, []string{"app"}, &config.Config{}, "", "analytics", sslFlags{}, false, "", "", "", uint16(port))
+	assert.NoError(t, err)
+	assert.Equal(t, connectionParams{database: "app", service: "analytics"}, params,
+		"the service file, not the defaults, fills in user, host and port")
+
+	params, err = resolveConnectionParams(cmd, []string{"app"}, &config.Config{}, "", "", sslFlags{}, false, "", "", "", uint16(port))
+	assert.NoError(t, err)
+	assert.Equal(t, "envhost", params.host)
+	assert.Equal(t, uint16(5432), params.port)
+}
+
+func TestApplySSL(t *testing.T) {
+	profile := config.ConnectionProfile{SSLMode: "verify-full", SSLRootCert: "ca.pem", SSLCert: "app.crt", SSLKey: "app.key", SSLPasswordFile: "app.pass"}
+
+	var params connectionParams
+	params.applySSL(sslFlags{mode: "require", key: "other.key"}, profile)
+	assert.Equal(t, "require", params.sslmode, "flags win over the profile")
+	assert.Equal(t, "ca.pem", params.sslrootcert)
+	assert.Equal(t, "app.crt", params.sslcert)
+	assert.Equal(t, "other.key", params.sslkey)
+	assert.Equal(t, "app.pass", params.sslpasswordFile)
+}
+
+func TestReadSSLPassword(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sslpassword")
+	require.NoError(t, os.WriteFile(path, []byte("s3cret\r\nignored\n"), 0o600))
+
+	password, err := readSSLPassword(path)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", password)
+
+	password, err = readSSLPassword("")
+	require.NoError(t, err)
+	assert.Empty(t, password)
+
+	_, err = readSSLPassword(filepath.Join(t.TempDir(), "missing"))
+	assert.ErrorContains(t, err, "reading sslpassword file")
+}
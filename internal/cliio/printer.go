@@ -28,6 +28,9 @@ const (
 	pagerModeAuto   = "auto"
 	pagerModeAlways = "always"
 	pagerModeNever  = "never"
+	// pagerModeBuiltin pages like auto, but with pgxcli's own result viewer
+	// instead of an external program.
+	pagerModeBuiltin = "builtin"
 
 	defaultTerminalHeight = 24
 	autoPagerMinBytes     = 4096
@@ -43,6 +46,7 @@ type Printer interface {
 	PrintTime(time time.Duration)
 	PrintViaPager(str string)
 	ShouldUsePager(str string) bool
+	BuiltinPager() bool
 }
 
 // pgxPrinter is the default Printer implementation used by the CLI.
@@ -91,7 +95,8 @@ func (p *pgxPrinter) SetErrOut(errOut io.Writer) {
 	p.errOut = errOut
 }
 
-// SetPagerMode configures pager behavior ("auto", "always", or "never").
+// SetPagerMode configures pager behavior ("auto", "always", "never", or
+// "builtin").
 func (p *pgxPrinter) SetPagerMode(mode string) error {
 	normalized := strings.ToLower(strings.TrimSpace(mode))
 	if normalized == "" {
@@ -99,11 +104,11 @@ func (p *pgxPrinter) SetPagerMode(mode string) error {
 	}
 
 	switch normalized {
-	case pagerModeAuto, pagerModeAlways, pagerModeNever:
+	case pagerModeAuto, pagerModeAlways, pagerModeNever, pagerModeBuiltin:
 		p.pagerMode = normalized
 		return nil
 	default:
-		return fmt.Errorf("invalid pager mode %q, expected one of: auto, always, never", mode)
+		return fmt.Errorf("invalid pager mode %q, expected one of: auto, always, never, builtin", mode)
 	}
 }
 
@@ -126,7 +131,8 @@ func (p *pgxPrinter) PrintTime(time time.Duration) {
 func (p *pgxPrinter) PrintViaPager(str string) {
 	output := ensureTrailingNewline(str)
 
-	if !p.shouldUsePager(str) {
+	// the built-in viewer lives in the TUI, so plain output is never paged
+	if !p.shouldUsePager(str) || p.pagerMode == pagerModeBuiltin {
 		if _, err := io.WriteString(p.out, output); err != nil {
 			p.PrintError(err)
 		}
@@ -148,14 +154,21 @@ func (p *pgxPrinter) shouldUsePager(str string) bool {
 		return false
 	case pagerModeAlways:
 		return p.isTerminal && p.pagerSupported
+	case pagerModeBuiltin:
+		return p.isTerminal && p.exceedsScreen(str)
 	default:
 		if !p.isTerminal || !p.pagerSupported {
 			return false
 		}
-		return len(str) >= autoPagerMinBytes || lineCount(str) > p.autoPagerLineThreshold()
+		return p.exceedsScreen(str)
 	}
 }
 
+// exceedsScreen reports whether str is too long to read without paging.
+func (p *pgxPrinter) exceedsScreen(str string) bool {
+	return len(str) >= autoPagerMinBytes || lineCount(str) > p.autoPagerLineThreshold()
+}
+
 func (p *pgxPrinter) autoPagerLineThreshold() int {
 	if p.terminalHeight <= 2 {
 		return 1
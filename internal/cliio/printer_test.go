@@ -60,6 +60,9 @@ func TestSetPagerMode(t *testing.T) {
 	assert.NoError(t, p.SetPagerMode("never"))
 	assert.Equal(t, pagerModeNever, p.pagerMode)
 
+	assert.NoError(t, p.SetPagerMode("Builtin"))
+	assert.Equal(t, pagerModeBuiltin, p.pagerMode)
+
 	err := p.SetPagerMode("invalid")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid pager mode")
@@ -82,6 +85,12 @@ func TestShouldUsePager(t *testing.T) {
 	assert.False(t, basePrinter.shouldUsePager("small output"))
 	assert.True(t, basePrinter.shouldUsePager(strings.Repeat("a", autoPagerMinBytes)))
 	assert.True(t, basePrinter.shouldUsePager(strings.Repeat("line\n", 10)))
+
+	basePrinter.pagerMode = pagerModeBuiltin
+	basePrinter.pagerSupported = false
+	assert.False(t, basePrinter.shouldUsePager("small output"))
+	assert.True(t, basePrinter.shouldUsePager(strings.Repeat("line\n", 10)))
+	assert.True(t, basePrinter.BuiltinPager())
 }
 
 func TestLineCount(t *testing.T) {
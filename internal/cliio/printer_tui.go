@@ -6,6 +6,12 @@ func (p *pgxPrinter) ShouldUsePager(str string) bool {
 	return p.shouldUsePager(str)
 }
 
+// BuiltinPager reports whether long output goes to the built-in viewer
+// rather than an external pager.
+func (p *pgxPrinter) BuiltinPager() bool {
+	return p.pagerMode == pagerModeBuiltin
+}
+
 func ResolvePagerCommand() (path string, args []string, ok bool) {
 	return resolvePagerCommand()
 }
@@ -25,16 +25,27 @@ const (
 type Config struct {
 	Main  MainConfig  `mapstructure:"main" toml:"main"`
 	Table TableConfig `mapstructure:"table" toml:"table"`
+	Keys  KeysConfig  `mapstructure:"keys" toml:"keys"`
+
+	Connections map[string]ConnectionProfile `mapstructure:"connections" toml:"connections"`
 }
 
 // MainConfig contains general CLI and session settings.
 type MainConfig struct {
-	Prompt      string               `mapstructure:"prompt" toml:"prompt"`
-	Style       SyntaxHighlightStyle `mapstructure:"style" toml:"style"`
-	HistoryFile string               `mapstructure:"history_file" toml:"history_file"`
-	LogFile     string               `mapstructure:"log_file" toml:"log_file"`
-	Pager       string               `mapstructure:"pager" toml:"pager"`
-	OnError     OnErrorAction        `mapstructure:"on_error" toml:"on_error"`
+	Prompt             string               `mapstructure:"prompt" toml:"prompt"`
+	Style              SyntaxHighlightStyle `mapstructure:"style" toml:"style"`
+	HistoryFile        string               `mapstructure:"history_file" toml:"history_file"`
+	HistoryScope       HistoryScope         `mapstructure:"history_scope" toml:"history_scope"`
+	HistoryIgnoreSpace bool                 `mapstructure:"history_ignore_space" toml:"history_ignore_space"`
+	RedactPatterns     []string             `mapstructure:"redact_patterns" toml:"redact_patterns"`
+	LogFile            string               `mapstructure:"log_file" toml:"log_file"`
+	Pager              string               `mapstructure:"pager" toml:"pager"`
+	OnError            OnErrorAction        `mapstructure:"on_error" toml:"on_error"`
+	Autocommit         bool                 `mapstructure:"autocommit" toml:"autocommit"`
+	OnErrorRollback    OnErrorRollback      `mapstructure:"on_error_rollback" toml:"on_error_rollback"`
+	ViMode             bool                 `mapstructure:"vi_mode" toml:"vi_mode"`
+	PasswordCommand    string               `mapstructure:"password_command" toml:"password_command"`
+	PasswordStore      string               `mapstructure:"password_store" toml:"password_store"`
 }
 
 // TableConfig contains output table rendering settings.
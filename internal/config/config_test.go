@@ -1,10 +1,12 @@
 package config
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -33,6 +35,8 @@ func TestLoad_Success(t *testing.T) {
 	assert.Equal(t, "default", cfg.Main.LogFile)
 	assert.Equal(t, "auto", cfg.Main.Pager)
 	assert.Equal(t, OnErrorStop, cfg.Main.OnError)
+	assert.True(t, cfg.Main.Autocommit)
+	assert.Equal(t, OnErrorRollbackOff, cfg.Main.OnErrorRollback)
 }
 
 func TestLoad_UserConfigOverridesDefaults(t *testing.T) {
@@ -164,3 +168,46 @@ func TestEnsureUserConfig_CreatesDirectoryWithRestrictivePermissions(t *testing.
 	require.NoError(t, err)
 	assert.Equal(t, os.FileMode(0o700), info.Mode().Perm())
 }
+
+func TestWatch(t *testing.T) {
+	setIsolatedUserConfigEnv(t)
+	_, err := Load()
+	require.NoError(t, err)
+	userConfigPath, err := UserConfigPath()
+	require.NoError(t, err)
+
+	type change struct {
+		cfg *Config
+		err error
+	}
+	changes := make(chan change, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, Watch(ctx, func(cfg *Config, err error) {
+		changes <- change{cfg, err}
+	}))
+
+	next := func() change {
+		t.Helper()
+		select {
+		case c := <-changes:
+			return c
+		case <-time.After(5 * time.Second):
+			t.Fatal("config change not reported")
+			return change{}
+		}
+	}
+
+	require.NoError(t, os.WriteFile(userConfigPath, []byte("[table]\nstyle = \"markdown\"\n"), 0o644))
+	c := next()
+	require.NoError(t, c.err)
+	assert.Equal(t, TableStyle("markdown"), c.cfg.Table.Style)
+
+	// editors that save by renaming a new file over the old one
+	tmp := filepath.Join(filepath.Dir(userConfigPath), "config.toml.swp")
+	require.NoError(t, os.WriteFile(tmp, []byte("[main]\npager = \"sometimes\"\n"), 0o644))
+	require.NoError(t, os.Rename(tmp, userConfigPath))
+	c = next()
+	assert.ErrorContains(t, c.err, "pager mode must be one of")
+	assert.Nil(t, c.cfg)
+}
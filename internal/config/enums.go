@@ -180,6 +180,50 @@ func (a OnErrorAction) isValid() bool {
 	}
 }
 
+// OnErrorRollback controls whether a failed statement inside a transaction
+// block is rolled back on its own instead of aborting the whole transaction.
+type OnErrorRollback string
+
+const (
+	// OnErrorRollbackOff leaves the transaction aborted after an error.
+	OnErrorRollbackOff OnErrorRollback = "off"
+	// OnErrorRollbackOn rolls back only the failed statement.
+	OnErrorRollbackOn OnErrorRollback = "on"
+	// OnErrorRollbackInteractive behaves like on when input comes from a terminal.
+	OnErrorRollbackInteractive OnErrorRollback = "interactive"
+)
+
+func (r OnErrorRollback) isValid() bool {
+	switch r {
+	case OnErrorRollbackOff, OnErrorRollbackOn, OnErrorRollbackInteractive:
+		return true
+	default:
+		return false
+	}
+}
+
+// HistoryScope controls which past queries are offered by the up arrow,
+// ctrl+r and \history.
+type HistoryScope string
+
+const (
+	// HistoryScopeGlobal shares one history across all connections.
+	HistoryScopeGlobal HistoryScope = "global"
+	// HistoryScopeHost keeps a history per server host.
+	HistoryScopeHost HistoryScope = "host"
+	// HistoryScopeDatabase keeps a history per database on each host.
+	HistoryScopeDatabase HistoryScope = "database"
+)
+
+func (s HistoryScope) isValid() bool {
+	switch s {
+	case HistoryScopeGlobal, HistoryScopeHost, HistoryScopeDatabase:
+		return true
+	default:
+		return false
+	}
+}
+
 type TableColor string
 
 const (
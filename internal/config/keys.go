@@ -0,0 +1,116 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// KeysConfig maps editing actions to the keys that trigger them, replacing
+// the default keys of each listed action.
+type KeysConfig map[string][]string
+
+// KeyActions lists the editing actions that can be rebound in [keys].
+var KeyActions = []string{
+	"character_backward",
+	"character_forward",
+	"word_backward",
+	"word_forward",
+	"line_start",
+	"line_end",
+	"line_previous",
+	"line_next",
+	"input_begin",
+	"input_end",
+	"delete_character_backward",
+	"delete_character_forward",
+	"delete_word_backward",
+	"delete_word_forward",
+	"delete_before_cursor",
+	"delete_after_cursor",
+	"insert_newline",
+	"paste",
+	"end_of_input",
+	"history_previous",
+	"history_next",
+	"reverse_search",
+	"external_edit",
+}
+
+var keyModifiers = map[string]struct{}{
+	"ctrl": {}, "alt": {}, "shift": {}, "meta": {}, "super": {}, "hyper": {},
+}
+
+var namedKeys = map[string]struct{}{
+	"up": {}, "down": {}, "left": {}, "right": {},
+	"home": {}, "end": {}, "pgup": {}, "pgdown": {},
+	"insert": {}, "delete": {}, "backspace": {},
+	"enter": {}, "tab": {}, "esc": {}, "space": {},
+}
+
+// validateKeys checks that each action in [keys] exists, has at least one
+// well-formed key, and that no key triggers two actions.
+func validateKeys(keys KeysConfig) []error {
+	var errs []error
+	boundTo := make(map[string]string)
+
+	actions := make([]string, 0, len(keys))
+	for action := range keys {
+		actions = append(actions, action)
+	}
+	slices.Sort(actions)
+
+	for _, action := range actions {
+		if !slices.Contains(KeyActions, action) {
+			errs = append(errs, fmt.Errorf("keys: unknown action %q", action))
+			continue
+		}
+		if len(keys[action]) == 0 {
+			errs = append(errs, fmt.Errorf("keys.%s: at least one key is required", action))
+		}
+		for _, k := range keys[action] {
+			if !validKey(k) {
+				errs = append(errs, fmt.Errorf("keys.%s: invalid key %q, expected e.g. \"ctrl+e\", \"alt+b\", \"f2\" or \"up\"", action, k))
+				continue
+			}
+			if other, ok := boundTo[k]; ok {
+				errs = append(errs, fmt.Errorf("keys: %q is bound to both %s and %s", k, other, action))
+				continue
+			}
+			boundTo[k] = action
+		}
+	}
+	return errs
+}
+
+// validKey reports whether k is a key in the form used by Bubble Tea: any
+// number of modifiers followed by a single character or a named key, joined
+// by "+".
+func validKey(k string) bool {
+	if utf8.RuneCountInString(k) == 1 {
+		return k != " "
+	}
+
+	parts := strings.Split(k, "+")
+	last := parts[len(parts)-1]
+	if last == "" && len(parts) > 2 && parts[len(parts)-2] == "" {
+		// a modified "+" key, e.g. "ctrl++"
+		parts, last = parts[:len(parts)-1], "+"
+	}
+	for _, mod := range parts[:len(parts)-1] {
+		if _, ok := keyModifiers[mod]; !ok {
+			return false
+		}
+	}
+	return utf8.RuneCountInString(last) == 1 || isNamedKey(last)
+}
+
+func isNamedKey(name string) bool {
+	if _, ok := namedKeys[name]; ok {
+		return true
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(name, "f"))
+	return strings.HasPrefix(name, "f") && err == nil && n >= 1 && n <= 20
+}
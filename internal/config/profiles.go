@@ -0,0 +1,190 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ConnectionProfile is a named set of connection settings from a
+// [connections.<name>] table, selected with pgxcli @name or --profile name.
+// Flags given on the command line take precedence over the profile.
+type ConnectionProfile struct {
+	Host   string `mapstructure:"host" toml:"host"`
+	Port   uint16 `mapstructure:"port" toml:"port"`
+	DBName string `mapstructure:"dbname" toml:"dbname"`
+	User   string `mapstructure:"user" toml:"user"`
+	// SSLMode is a libpq sslmode, such as "require" or "verify-full".
+	SSLMode string `mapstructure:"sslmode" toml:"sslmode"`
+	// SSLRootCert is the file of the CA certificates the server is verified
+	// against; SSLCert and SSLKey are the client certificate and its key.
+	SSLRootCert string `mapstructure:"sslrootcert" toml:"sslrootcert"`
+	SSLCert     string `mapstructure:"sslcert" toml:"sslcert"`
+	SSLKey      string `mapstructure:"sslkey" toml:"sslkey"`
+	// SSLPasswordFile holds the passphrase of an encrypted SSLKey.
+	SSLPasswordFile string `mapstructure:"sslpassword_file" toml:"sslpassword_file"`
+	// SSH tunnels the connection through a user@host[:port] bastion, with
+	// the SSH agent or SSHIdentity, a private key file.
+	SSH         string `mapstructure:"ssh" toml:"ssh"`
+	SSHIdentity string `mapstructure:"ssh_identity" toml:"ssh_identity"`
+	// PasswordCommand, when set, replaces the password_command of [main].
+	PasswordCommand string `mapstructure:"password_command" toml:"password_command"`
+	// Options are server options sent at connection start, e.g.
+	// "-c search_path=app".
+	Options string `mapstructure:"options" toml:"options"`
+	// Prompt, when set, replaces the prompt of [main].
+	Prompt string `mapstructure:"prompt" toml:"prompt"`
+	// Color tags the session in the status bar, e.g. "red" for production.
+	Color ProfileColor `mapstructure:"color" toml:"color"`
+}
+
+// ProfileColor is a color name such as "red" or "cyan+" (bright), or a hex
+// color such as "#ff8800".
+type ProfileColor string
+
+var profileColorCodes = map[string]int{
+	"black": 0, "red": 1, "green": 2, "yellow": 3,
+	"blue": 4, "magenta": 5, "cyan": 6, "white": 7,
+}
+
+var hexColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Code returns the color as an ANSI color number or a hex color, the forms
+// accepted by lipgloss.Color, or "" when no color is set.
+func (c ProfileColor) Code() string {
+	if hexColor.MatchString(string(c)) {
+		return string(c)
+	}
+	name, bright := strings.CutSuffix(string(c), "+")
+	code, ok := profileColorCodes[name]
+	if !ok {
+		return ""
+	}
+	if bright {
+		code += 8
+	}
+	return strconv.Itoa(code)
+}
+
+func (c ProfileColor) isValid() bool {
+	return c == "" || c.Code() != ""
+}
+
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// profileName is the form of a profile name: it is a bare TOML key, so it
+// can be written without quoting, and lower-case, since keys are read
+// case-insensitively.
+var profileName = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// Profile returns the connection profile called name, ignoring case.
+func (c *Config) Profile(name string) (ConnectionProfile, bool) {
+	profile, ok := c.Connections[strings.ToLower(name)]
+	return profile, ok
+}
+
+// ProfileNames returns the names of the connection profiles, sorted.
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Connections))
+	for name := range c.Connections {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// validateConnections checks the TLS settings and color of each profile.
+func validateConnections(connections map[string]ConnectionProfile) []error {
+	var errs []error
+	names := make([]string, 0, len(connections))
+	for name := range connections {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		profile := connections[name]
+		if profile.SSLMode != "" && !slices.Contains(sslModes, profile.SSLMode) {
+			errs = append(errs, fmt.Errorf("connections.%s.sslmode must be one of: %s", name, strings.Join(sslModes, ", ")))
+		}
+		if (profile.SSLCert == "") != (profile.SSLKey == "") {
+			errs = append(errs, fmt.Errorf("connections.%s: sslcert and sslkey must be set together", name))
+		}
+		if !profile.Color.isValid() {
+			errs = append(errs, fmt.Errorf("connections.%s.color %q must be a color name such as \"red\" or \"cyan+\", or a hex color such as \"#ff8800\"", name, profile.Color))
+		}
+	}
+	return errs
+}
+
+// SaveProfile appends a [connections.<name>] table for profile to the user
+// config file. The rest of the file, comments included, is left untouched.
+func (c *Config) SaveProfile(name string, profile ConnectionProfile) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !profileName.MatchString(name) {
+		return fmt.Errorf("profile name %q may only contain letters, digits, '-' and '_'", name)
+	}
+	if _, ok := c.Connections[name]; ok {
+		return fmt.Errorf("profile %q already exists", name)
+	}
+
+	path, err := UserConfigPath()
+	if err != nil {
+		return err
+	}
+	if err := ensureUserConfig(path); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open user config: %w", err)
+	}
+	if _, err := f.WriteString(profileTable(name, profile)); err != nil {
+		f.Close()
+		return fmt.Errorf("save profile: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("save profile: %w", err)
+	}
+
+	if c.Connections == nil {
+		c.Connections = make(map[string]ConnectionProfile)
+	}
+	c.Connections[name] = profile
+	return nil
+}
+
+// profileTable renders profile as a TOML table, leaving out empty settings.
+func profileTable(name string, profile ConnectionProfile) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "\n[connections.%s]\n", name)
+	set := func(key, value string) {
+		if value != "" {
+			// a JSON string is also a valid TOML basic string
+			quoted, _ := json.Marshal(value)
+			fmt.Fprintf(&sb, "%s = %s\n", key, quoted)
+		}
+	}
+	set("host", profile.Host)
+	if profile.Port != 0 {
+		fmt.Fprintf(&sb, "port = %d\n", profile.Port)
+	}
+	set("dbname", profile.DBName)
+	set("user", profile.User)
+	set("sslmode", profile.SSLMode)
+	set("sslrootcert", profile.SSLRootCert)
+	set("sslcert", profile.SSLCert)
+	set("sslkey", profile.SSLKey)
+	set("sslpassword_file", profile.SSLPasswordFile)
+	set("ssh", profile.SSH)
+	set("ssh_identity", profile.SSHIdentity)
+	set("password_command", profile.PasswordCommand)
+	set("options", profile.Options)
+	set("prompt", profile.Prompt)
+	set("color", string(profile.Color))
+	return sb.String()
+}
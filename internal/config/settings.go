@@ -0,0 +1,100 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// liveSetting reads and writes one of the settings that a running session
+// picks up without a restart.
+type liveSetting struct {
+	get func(*Config) string
+	set func(*Config, string)
+}
+
+// liveKeys are the keys of liveSettings, in the order of the config file.
+var liveKeys = []string{
+	"prompt",
+	"style",
+	"pager",
+	"on_error",
+	"table.style",
+	"table.color.header",
+	"table.color.column",
+	"table.color.caption",
+}
+
+var liveSettings = map[string]liveSetting{
+	"prompt": {
+		get: func(c *Config) string { return c.Main.Prompt },
+		set: func(c *Config, v string) { c.Main.Prompt = v },
+	},
+	"style": {
+		get: func(c *Config) string { return string(c.Main.Style) },
+		set: func(c *Config, v string) { c.Main.Style = SyntaxHighlightStyle(v) },
+	},
+	"pager": {
+		get: func(c *Config) string { return c.Main.Pager },
+		set: func(c *Config, v string) { c.Main.Pager = v },
+	},
+	"on_error": {
+		get: func(c *Config) string { return string(c.Main.OnError) },
+		set: func(c *Config, v string) { c.Main.OnError = OnErrorAction(v) },
+	},
+	"table.style": {
+		get: func(c *Config) string { return string(c.Table.Style) },
+		set: func(c *Config, v string) { c.Table.Style = TableStyle(v) },
+	},
+	"table.color.header": {
+		get: func(c *Config) string { return string(c.Table.Color.Header) },
+		set: func(c *Config, v string) { c.Table.Color.Header = TableColor(v) },
+	},
+	"table.color.column": {
+		get: func(c *Config) string { return string(c.Table.Color.Column) },
+		set: func(c *Config, v string) { c.Table.Color.Column = TableColor(v) },
+	},
+	"table.color.caption": {
+		get: func(c *Config) string { return string(c.Table.Color.Caption) },
+		set: func(c *Config, v string) { c.Table.Color.Caption = TableColor(v) },
+	},
+}
+
+// LiveKeys returns the settings that take effect in a running session, in
+// the order of the config file. Other settings are read at startup only.
+func LiveKeys() []string {
+	return slices.Clone(liveKeys)
+}
+
+// Get returns the value of the live setting key, e.g. "table.style".
+func (c *Config) Get(key string) (string, error) {
+	setting, err := lookupLiveSetting(key)
+	if err != nil {
+		return "", err
+	}
+	return setting.get(c), nil
+}
+
+// Set changes the live setting key to value. The value is checked as it is
+// when the config file is loaded; c is left unchanged when it is invalid.
+func (c *Config) Set(key, value string) error {
+	setting, err := lookupLiveSetting(key)
+	if err != nil {
+		return err
+	}
+	next := *c
+	setting.set(&next, value)
+	if err := validate(next); err != nil {
+		return err
+	}
+	*c = next
+	return nil
+}
+
+func lookupLiveSetting(key string) (liveSetting, error) {
+	setting, ok := liveSettings[strings.ToLower(key)]
+	if !ok {
+		return liveSetting{}, fmt.Errorf("unknown setting %q; settings that can be changed are: %s", key, strings.Join(liveKeys, ", "))
+	}
+	return setting, nil
+}
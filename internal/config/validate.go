@@ -2,6 +2,8 @@ package config
 
 import (
 	"errors"
+	"fmt"
+	"regexp"
 	"strings"
 )
 
@@ -19,6 +21,9 @@ func validate(cfg Config) error {
 	if cfg.Main.HistoryFile == "" {
 		errs = append(errs, errors.New("history file path must not be empty"))
 	}
+	if !cfg.Main.HistoryScope.isValid() {
+		errs = append(errs, errors.New("history_scope must be one of: global, host, database"))
+	}
 	if cfg.Main.LogFile == "" {
 		errs = append(errs, errors.New("log file path must not be empty"))
 	}
@@ -28,9 +33,9 @@ func validate(cfg Config) error {
 		errs = append(errs, errors.New("pager mode must not be empty"))
 	} else {
 		switch pagerMode {
-		case "auto", "always", "never":
+		case "auto", "always", "never", "builtin":
 		default:
-			errs = append(errs, errors.New("pager mode must be one of: auto, always, never"))
+			errs = append(errs, errors.New("pager mode must be one of: auto, always, never, builtin"))
 		}
 	}
 	onError := cfg.Main.OnError
@@ -39,19 +44,36 @@ func validate(cfg Config) error {
 	} else if !onError.isValid() {
 		errs = append(errs, errors.New("on_error action must be one of: STOP, RESUME"))
 	}
-	if !cfg.Table.Style.isValid() {
+	if !cfg.Main.OnErrorRollback.isValid() {
+		errs = append(errs, errors.New("on_error_rollback must be one of: off, on, interactive"))
+	}
+	for _, pattern := range cfg.Main.RedactPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, fmt.Errorf("redact_patterns: invalid pattern %q: %w", pattern, err))
+		}
+	}
+	errs = append(errs, validateTable(cfg.Table)...)
+	errs = append(errs, validateKeys(cfg.Keys)...)
+	errs = append(errs, validateConnections(cfg.Connections)...)
+
+	return errors.Join(errs...)
+}
+
+// validateTable checks the [table] style and colors.
+func validateTable(table TableConfig) []error {
+	var errs []error
+	if !table.Style.isValid() {
 		errs = append(errs, errors.New("table style must be a valid style"))
 	}
 
-	if !cfg.Table.Color.Header.isValid() {
+	if !table.Color.Header.isValid() {
 		errs = append(errs, errors.New("table color header must be a valid color"))
 	}
-	if !cfg.Table.Color.Column.isValid() {
+	if !table.Color.Column.isValid() {
 		errs = append(errs, errors.New("table color column must be a valid color"))
 	}
-	if !cfg.Table.Color.Caption.isValid() {
+	if !table.Color.Caption.isValid() {
 		errs = append(errs, errors.New("table color caption must be a valid color"))
 	}
-
-	return errors.Join(errs...)
+	return errs
 }
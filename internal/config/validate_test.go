@@ -12,12 +12,14 @@ import (
 func TestValidate_Success(t *testing.T) {
 	cfg := Config{
 		Main: MainConfig{
-			Prompt:      "test> ",
-			Style:       SyntaxStyleMonokai,
-			HistoryFile: "default",
-			LogFile:     "default",
-			Pager:       "auto",
-			OnError:     OnErrorStop,
+			Prompt:          "test> ",
+			Style:           SyntaxStyleMonokai,
+			HistoryFile:     "default",
+			HistoryScope:    HistoryScopeGlobal,
+			LogFile:         "default",
+			Pager:           "auto",
+			OnError:         OnErrorStop,
+			OnErrorRollback: OnErrorRollbackOff,
 		},
 		Table: TableConfig{
 			Style: StyleDefault,
@@ -144,7 +146,7 @@ on_error = "STOP"
 	_, err = Load()
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "validate config")
-	assert.Contains(t, err.Error(), "pager mode must be one of: auto, always, never")
+	assert.Contains(t, err.Error(), "pager mode must be one of: auto, always, never, builtin")
 }
 
 func TestLoad_ValidationFailsOnInvalidOnErrorAction(t *testing.T) {
@@ -170,6 +172,47 @@ on_error = "continue"
 	assert.Contains(t, err.Error(), "on_error action must be one of: STOP, RESUME")
 }
 
+func TestLoad_ValidationFailsOnInvalidOnErrorRollback(t *testing.T) {
+	setIsolatedUserConfigEnv(t)
+
+	userConfigPath, err := UserConfigPath()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(userConfigPath), 0o700))
+
+	userConfig := `[main]
+prompt = "test> "
+style = "monokai"
+history_file = "default"
+log_file = "default"
+pager = "auto"
+on_error = "STOP"
+on_error_rollback = "sometimes"
+`
+	require.NoError(t, os.WriteFile(userConfigPath, []byte(userConfig), 0o644))
+
+	_, err = Load()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "validate config")
+	assert.Contains(t, err.Error(), "on_error_rollback must be one of: off, on, interactive")
+}
+
+func TestLoad_ValidationFailsOnInvalidHistoryScope(t *testing.T) {
+	setIsolatedUserConfigEnv(t)
+
+	userConfigPath, err := UserConfigPath()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(userConfigPath), 0o700))
+
+	userConfig := `[main]
+history_scope = "schema"
+`
+	require.NoError(t, os.WriteFile(userConfigPath, []byte(userConfig), 0o644))
+
+	_, err = Load()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "history_scope must be one of: global, host, database")
+}
+
 func TestLoad_ValidationAllowsTrimmedPagerMode(t *testing.T) {
 	setIsolatedUserConfigEnv(t)
 
@@ -192,3 +235,229 @@ on_error = "STOP"
 	assert.Equal(t, " auto ", cfg.Main.Pager)
 	assert.Equal(t, OnErrorStop, cfg.Main.OnError)
 }
+
+func TestValidateKeys(t *testing.T) {
+	testCases := []struct {
+		name    string
+		keys    KeysConfig
+		wantErr []string
+	}{
+		{
+			name: "valid bindings",
+			keys: KeysConfig{
+				"external_edit":    {"ctrl+x"},
+				"history_previous": {"up", "ctrl+p"},
+				"word_forward":     {"alt+f", "ctrl+right"},
+				"paste":            {"f2"},
+				"insert_newline":   {"ctrl++", "shift+enter"},
+			},
+		},
+		{
+			name:    "unknown action",
+			keys:    KeysConfig{"launch_rockets": {"ctrl+l"}},
+			wantErr: []string{`keys: unknown action "launch_rockets"`},
+		},
+		{
+			name:    "no keys",
+			keys:    KeysConfig{"paste": {}},
+			wantErr: []string{"keys.paste: at least one key is required"},
+		},
+		{
+			name: "malformed keys",
+			keys: KeysConfig{"paste": {"ctrl+", "control+v", "f21", "ctrl+pageup"}},
+			wantErr: []string{
+				`keys.paste: invalid key "ctrl+"`,
+				`keys.paste: invalid key "control+v"`,
+				`keys.paste: invalid key "f21"`,
+				`keys.paste: invalid key "ctrl+pageup"`,
+			},
+		},
+		{
+			name:    "key bound twice",
+			keys:    KeysConfig{"paste": {"ctrl+y"}, "external_edit": {"ctrl+y"}},
+			wantErr: []string{`keys: "ctrl+y" is bound to both external_edit and paste`},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			errs := validateKeys(tc.keys)
+			require.Len(t, errs, len(tc.wantErr))
+			for i, want := range tc.wantErr {
+				assert.ErrorContains(t, errs[i], want)
+			}
+		})
+	}
+}
+
+func TestLoad_KeysAndViMode(t *testing.T) {
+	setIsolatedUserConfigEnv(t)
+
+	userConfigPath, err := UserConfigPath()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(userConfigPath), 0o700))
+
+	userConfig := `[main]
+vi_mode = true
+
+[keys]
+external_edit = ["ctrl+x"]
+paste = "ctrl+y"
+`
+	require.NoError(t, os.WriteFile(userConfigPath, []byte(userConfig), 0o644))
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.True(t, cfg.Main.ViMode)
+	assert.Equal(t, KeysConfig{"external_edit": {"ctrl+x"}, "paste": {"ctrl+y"}}, cfg.Keys)
+
+	require.NoError(t, os.WriteFile(userConfigPath, []byte("[keys]\nexternal_edit = [\"ctrl+\"]\n"), 0o644))
+	_, err = Load()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `keys.external_edit: invalid key "ctrl+"`)
+}
+
+func TestLoad_RedactPatterns(t *testing.T) {
+	setIsolatedUserConfigEnv(t)
+
+	userConfigPath, err := UserConfigPath()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(userConfigPath), 0o700))
+
+	userConfig := `[main]
+history_ignore_space = true
+redact_patterns = ["sk_live_\\w+"]
+`
+	require.NoError(t, os.WriteFile(userConfigPath, []byte(userConfig), 0o644))
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.True(t, cfg.Main.HistoryIgnoreSpace)
+	assert.Equal(t, []string{`sk_live_\w+`}, cfg.Main.RedactPatterns)
+
+	require.NoError(t, os.WriteFile(userConfigPath, []byte("[main]\nredact_patterns = [\"(\"]\n"), 0o644))
+	_, err = Load()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `redact_patterns: invalid pattern "("`)
+}
+
+func TestLoad_ConnectionProfiles(t *testing.T) {
+	setIsolatedUserConfigEnv(t)
+
+	userConfigPath, err := UserConfigPath()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(userConfigPath), 0o700))
+
+	userConfig := `[connections.prod]
+host = "db.example.com"
+port = 6432
+dbname = "app"
+user = "readonly"
+sslmode = "verify-full"
+sslrootcert = "/etc/ssl/prod-ca.pem"
+ssh = "deploy@bastion.example.com"
+options = "-c search_path=app"
+prompt = "prod> "
+color = "red+"
+
+[connections.Dev]
+host = "localhost"
+`
+	require.NoError(t, os.WriteFile(userConfigPath, []byte(userConfig), 0o644))
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dev", "prod"}, cfg.ProfileNames())
+
+	prod, ok := cfg.Profile("PROD")
+	require.True(t, ok)
+	assert.Equal(t, ConnectionProfile{
+		Host:        "db.example.com",
+		Port:        6432,
+		DBName:      "app",
+		User:        "readonly",
+		SSLMode:     "verify-full",
+		SSLRootCert: "/etc/ssl/prod-ca.pem",
+		SSH:         "deploy@bastion.example.com",
+		Options:     "-c search_path=app",
+		Prompt:      "prod> ",
+		Color:       "red+",
+	}, prod)
+	assert.Equal(t, "9", prod.Color.Code())
+
+	_, ok = cfg.Profile("staging")
+	assert.False(t, ok)
+
+	require.NoError(t, os.WriteFile(userConfigPath, []byte("[connections.prod]\nsslmode = \"sometimes\"\nsslkey = \"client.key\"\ncolor = \"pink\"\n"), 0o644))
+	_, err = Load()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "connections.prod.sslmode must be one of: disable, allow, prefer, require, verify-ca, verify-full")
+	assert.Contains(t, err.Error(), "connections.prod: sslcert and sslkey must be set together")
+	assert.Contains(t, err.Error(), `connections.prod.color "pink" must be a color name`)
+}
+
+func TestSaveProfile(t *testing.T) {
+	setIsolatedUserConfigEnv(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+
+	profile := ConnectionProfile{Host: "db.example.com", Port: 5432, DBName: "app", User: `o"brien`}
+	require.NoError(t, cfg.SaveProfile("Staging", profile))
+	assert.ErrorContains(t, cfg.SaveProfile("staging", profile), `profile "staging" already exists`)
+	assert.ErrorContains(t, cfg.SaveProfile("my profile", profile), "may only contain letters, digits")
+
+	reloaded, err := Load()
+	require.NoError(t, err)
+	saved, ok := reloaded.Profile("staging")
+	require.True(t, ok)
+	assert.Equal(t, profile, saved)
+}
+
+func TestProfileColorCode(t *testing.T) {
+	testCases := []struct {
+		color ProfileColor
+		want  string
+	}{
+		{"", ""},
+		{"red", "1"},
+		{"white+", "15"},
+		{"#FF8800", "#FF8800"},
+		{"pink", ""},
+		{"#ff88", ""},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.want, tc.color.Code(), tc.color)
+	}
+}
+
+func TestConfigGetAndSet(t *testing.T) {
+	cfg, err := GetDefaultConfig()
+	require.NoError(t, err)
+
+	for _, key := range LiveKeys() {
+		_, err := cfg.Get(key)
+		assert.NoError(t, err, key)
+	}
+
+	require.NoError(t, cfg.Set("table.style", "markdown"))
+	assert.Equal(t, TableStyle("markdown"), cfg.Table.Style)
+	require.NoError(t, cfg.Set("Style", "dracula"))
+	value, err := cfg.Get("style")
+	require.NoError(t, err)
+	assert.Equal(t, "dracula", value)
+
+	err = cfg.Set("pager", "sometimes")
+	assert.ErrorContains(t, err, "pager mode must be one of")
+	assert.Equal(t, "auto", cfg.Main.Pager)
+
+	err = cfg.Set("on_error", "resume")
+	assert.ErrorContains(t, err, "on_error action must be one of: STOP, RESUME")
+	assert.Equal(t, OnErrorStop, cfg.Main.OnError)
+
+	err = cfg.Set("history_file", "/tmp/history")
+	assert.ErrorContains(t, err, `unknown setting "history_file"`)
+	_, err = cfg.Get("nope")
+	assert.ErrorContains(t, err, "settings that can be changed are: prompt, style")
+}
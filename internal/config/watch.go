@@ -0,0 +1,65 @@
+package config
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDelay lets the burst of events of a single save settle before the
+// file is read.
+const reloadDelay = 100 * time.Millisecond
+
+// Watch calls onChange with the reloaded configuration, or with the error
+// loading it, each time the user config file is saved, until ctx is done.
+// The directory of the file is watched rather than the file itself, since
+// many editors save by replacing the file.
+func Watch(ctx context.Context, onChange func(*Config, error)) error {
+	path, err := UserConfigPath()
+	if err != nil {
+		return err
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return err
+	}
+
+	go watchFile(ctx, watcher, path, onChange)
+	return nil
+}
+
+func watchFile(ctx context.Context, watcher *fsnotify.Watcher, path string, onChange func(*Config, error)) {
+	defer watcher.Close()
+
+	var reload <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) == path && event.Has(fsnotify.Write|fsnotify.Create) {
+				reload = time.After(reloadDelay)
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			onChange(nil, err)
+
+		case <-reload:
+			reload = nil
+			onChange(Load())
+		}
+	}
+}
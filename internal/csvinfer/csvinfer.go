@@ -0,0 +1,261 @@
+// Package csvinfer infers PostgreSQL column types from sampled CSV data.
+//
+// Each value is classified as the narrowest type that can hold it, and the
+// types of a column are merged into the narrowest type that holds them all.
+// Empty values are treated as NULL and do not affect the inferred type.
+package csvinfer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Type is an inferred column type, ordered from narrowest to widest within
+// each family.
+type Type int
+
+const (
+	// Unknown means no non-empty value has been seen.
+	Unknown Type = iota
+	Integer
+	Numeric
+	Boolean
+	Date
+	Timestamp
+	Text
+)
+
+// String returns the PostgreSQL type name used in CREATE TABLE.
+func (t Type) String() string {
+	switch t {
+	case Integer:
+		return "bigint"
+	case Numeric:
+		return "numeric"
+	case Boolean:
+		return "boolean"
+	case Date:
+		return "date"
+	case Timestamp:
+		return "timestamp"
+	default:
+		return "text"
+	}
+}
+
+var numericPattern = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+
+var dateLayouts = []string{"2006-01-02"}
+
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04",
+}
+
+// InferValue returns the narrowest type that can hold s. An empty string
+// (after trimming) is Unknown.
+func InferValue(s string) Type {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "":
+		return Unknown
+	case isInteger(s):
+		return Integer
+	case numericPattern.MatchString(s):
+		return Numeric
+	case isBoolean(s):
+		return Boolean
+	case matchesLayout(s, dateLayouts):
+		return Date
+	case matchesLayout(s, timestampLayouts):
+		return Timestamp
+	default:
+		return Text
+	}
+}
+
+func isInteger(s string) bool {
+	_, err := strconv.ParseInt(s, 10, 64)
+	return err == nil
+}
+
+func isBoolean(s string) bool {
+	switch strings.ToLower(s) {
+	case "true", "false", "t", "f", "yes", "no":
+		return true
+	}
+	return false
+}
+
+func matchesLayout(s string, layouts []string) bool {
+	for _, layout := range layouts {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Merge returns the narrowest type that holds values of both a and b.
+func Merge(a, b Type) Type {
+	switch {
+	case a == b || b == Unknown:
+		return a
+	case a == Unknown:
+		return b
+	case a <= Numeric && b <= Numeric:
+		return Numeric
+	case (a == Date || a == Timestamp) && (b == Date || b == Timestamp):
+		return Timestamp
+	default:
+		return Text
+	}
+}
+
+// Accepts reports whether s is a valid value for a column of type t. Empty
+// values are accepted as NULL.
+func (t Type) Accepts(s string) bool {
+	v := InferValue(s)
+	return v == Unknown || Merge(t, v) == t
+}
+
+// Column describes one inferred column. Columns are always nullable, since
+// a sample cannot prove that a value is never missing.
+type Column struct {
+	Name string
+	Type Type
+}
+
+// Infer derives columns from a header and sampled rows. Column names are
+// normalized with Identifier; missing or duplicate names get a numeric
+// suffix. A column with no values is inferred as text.
+func Infer(header []string, rows [][]string) []Column {
+	cols := make([]Column, len(header))
+	seen := make(map[string]int, len(header))
+	for i, h := range header {
+		name := Identifier(h)
+		if name == "" {
+			name = fmt.Sprintf("column_%d", i+1)
+		}
+		if n := seen[name]; n > 0 {
+			seen[name]++
+			name = fmt.Sprintf("%s_%d", name, n+1)
+		} else {
+			seen[name] = 1
+		}
+		cols[i].Name = name
+	}
+
+	for _, row := range rows {
+		for i := range cols {
+			if i >= len(row) {
+				continue
+			}
+			cols[i].Type = Merge(cols[i].Type, InferValue(row[i]))
+		}
+	}
+
+	for i := range cols {
+		if cols[i].Type == Unknown {
+			cols[i].Type = Text
+		}
+	}
+	return cols
+}
+
+// Identifier turns s into a lower-case SQL identifier made of letters,
+// digits and underscores. It returns an empty string when nothing is left.
+func Identifier(s string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			underscore = false
+			continue
+		}
+		if !underscore && b.Len() > 0 {
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+	name := strings.TrimSuffix(b.String(), "_")
+	if name != "" && unicode.IsDigit(rune(name[0])) {
+		name = "_" + name
+	}
+	return name
+}
+
+// CreateTable returns a CREATE TABLE statement for the inferred columns.
+// table may be schema-qualified with a dot.
+func CreateTable(table string, cols []Column) string {
+	var b strings.Builder
+	b.WriteString("CREATE TABLE ")
+	b.WriteString(pgx.Identifier(strings.Split(table, ".")).Sanitize())
+	b.WriteString(" (\n")
+	for i, col := range cols {
+		fmt.Fprintf(&b, "    %s %s", pgx.Identifier{col.Name}.Sanitize(), col.Type)
+		if i < len(cols)-1 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('\n')
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+// Sample reads the header and up to n records from r.
+func Sample(r *csv.Reader, n int) ([]string, [][]string, error) {
+	header, err := r.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, errors.New("file is empty")
+		}
+		return nil, nil, err
+	}
+
+	rows := make([][]string, 0, n)
+	for len(rows) < n {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) && errors.Is(parseErr.Err, csv.ErrFieldCount) {
+			continue // malformed rows are rejected at load time
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		rows = append(rows, record)
+	}
+	return header, rows, nil
+}
+
+// Validate checks a record against the inferred columns, returning an error
+// describing the first problem.
+func Validate(cols []Column, record []string) error {
+	if len(record) != len(cols) {
+		return fmt.Errorf("expected %d fields, got %d", len(cols), len(record))
+	}
+	for i, col := range cols {
+		value := record[i]
+		if !col.Type.Accepts(value) {
+			return fmt.Errorf("column %q: %q is not a valid %s", col.Name, value, col.Type)
+		}
+	}
+	return nil
+}
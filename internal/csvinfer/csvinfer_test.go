@@ -0,0 +1,155 @@
+package csvinfer_test
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/balaji01-4d/pgxcli/internal/csvinfer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInferValue(t *testing.T) {
+	tests := []struct {
+		value string
+		want  csvinfer.Type
+	}{
+		{"", csvinfer.Unknown},
+		{"   ", csvinfer.Unknown},
+		{"42", csvinfer.Integer},
+		{"-7", csvinfer.Integer},
+		{"99999999999999999999", csvinfer.Numeric},
+		{"3.14", csvinfer.Numeric},
+		{".5", csvinfer.Numeric},
+		{"1e10", csvinfer.Numeric},
+		{"0x1p-2", csvinfer.Text},
+		{"NaN", csvinfer.Text},
+		{"true", csvinfer.Boolean},
+		{"F", csvinfer.Boolean},
+		{"yes", csvinfer.Boolean},
+		{"2024-02-29", csvinfer.Date},
+		{"2024-02-30", csvinfer.Text},
+		{"2024-02-29 13:45:00", csvinfer.Timestamp},
+		{"2024-02-29T13:45:00.123Z", csvinfer.Timestamp},
+		{"2024-02-29 13:45:00+02", csvinfer.Timestamp},
+		{"hello", csvinfer.Text},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.want, csvinfer.InferValue(tt.value))
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b csvinfer.Type
+		want csvinfer.Type
+	}{
+		{"SameType", csvinfer.Date, csvinfer.Date, csvinfer.Date},
+		{"UnknownLeft", csvinfer.Unknown, csvinfer.Boolean, csvinfer.Boolean},
+		{"UnknownRight", csvinfer.Integer, csvinfer.Unknown, csvinfer.Integer},
+		{"IntegerWidensToNumeric", csvinfer.Integer, csvinfer.Numeric, csvinfer.Numeric},
+		{"DateWidensToTimestamp", csvinfer.Timestamp, csvinfer.Date, csvinfer.Timestamp},
+		{"MixedFamiliesAreText", csvinfer.Integer, csvinfer.Boolean, csvinfer.Text},
+		{"TextAbsorbs", csvinfer.Text, csvinfer.Numeric, csvinfer.Text},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, csvinfer.Merge(tt.a, tt.b))
+		})
+	}
+}
+
+func TestInfer(t *testing.T) {
+	header := []string{"ID", "Unit Price", "Active?", "", "id", "Created At", "notes"}
+	rows := [][]string{
+		{"1", "9.99", "true", "x", "a", "2024-01-01", ""},
+		{"2", "10", "false", "y", "b", "2024-01-02 10:00:00", ""},
+		{"3", "", "t", "z", "c", "2024-01-03", ""},
+	}
+
+	cols := csvinfer.Infer(header, rows)
+	assert.Equal(t, []csvinfer.Column{
+		{Name: "id", Type: csvinfer.Integer},
+		{Name: "unit_price", Type: csvinfer.Numeric},
+		{Name: "active", Type: csvinfer.Boolean},
+		{Name: "column_4", Type: csvinfer.Text},
+		{Name: "id_2", Type: csvinfer.Text},
+		{Name: "created_at", Type: csvinfer.Timestamp},
+		{Name: "notes", Type: csvinfer.Text},
+	}, cols)
+}
+
+func TestIdentifier(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Customer Name", "customer_name"},
+		{"  total ($) ", "total"},
+		{"2024 sales", "_2024_sales"},
+		{"Ünïcode", "ünïcode"},
+		{"---", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.want, csvinfer.Identifier(tt.in))
+		})
+	}
+}
+
+func TestCreateTable(t *testing.T) {
+	cols := []csvinfer.Column{
+		{Name: "id", Type: csvinfer.Integer},
+		{Name: "order", Type: csvinfer.Text},
+	}
+
+	got := csvinfer.CreateTable("sales.orders", cols)
+	assert.Equal(t, "CREATE TABLE \"sales\".\"orders\" (\n    \"id\" bigint,\n    \"order\" text\n)", got)
+}
+
+func TestSample(t *testing.T) {
+	r := csv.NewReader(strings.NewReader("a,b\n1,2\n3\n4,5\n6,7\n"))
+	header, rows, err := csvinfer.Sample(r, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, header)
+	assert.Equal(t, [][]string{{"1", "2"}, {"4", "5"}}, rows)
+
+	_, _, err = csvinfer.Sample(csv.NewReader(strings.NewReader("")), 10)
+	assert.EqualError(t, err, "file is empty")
+}
+
+func TestValidate(t *testing.T) {
+	cols := []csvinfer.Column{
+		{Name: "id", Type: csvinfer.Integer},
+		{Name: "seen", Type: csvinfer.Timestamp},
+	}
+
+	tests := []struct {
+		name    string
+		record  []string
+		wantErr string
+	}{
+		{"Valid", []string{"1", "2024-01-01 00:00:00"}, ""},
+		{"DateFitsTimestamp", []string{"1", "2024-01-01"}, ""},
+		{"NullValues", []string{"", ""}, ""},
+		{"WrongType", []string{"1.5", ""}, `column "id": "1.5" is not a valid bigint`},
+		{"FieldCount", []string{"1"}, "expected 2 fields, got 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := csvinfer.Validate(cols, tt.record)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
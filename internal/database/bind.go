@@ -0,0 +1,91 @@
+package database
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/jackc/pgx/v5"
+)
+
+// BindAction carries the parameter values given to \bind.
+type BindAction struct {
+	Values []string
+}
+
+// ResultKind returns the special result kind for BindAction.
+func (a BindAction) ResultKind() pgxspecial.SpecialResultKind {
+	return Bind
+}
+
+// splitArgs splits backslash command arguments on whitespace. Single-quoted
+// arguments may contain whitespace; a doubled quote stands for one quote.
+func splitArgs(s string) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	inArg, quoted := false, false
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quoted && c == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			cur.WriteByte('\'')
+			i++
+		case c == '\'':
+			quoted = !quoted
+			inArg = true
+		case !quoted && (c == ' ' || c == '\t' || c == '\n'):
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteByte(c)
+			inArg = true
+		}
+	}
+
+	if quoted {
+		return nil, errors.New("unterminated quoted string")
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
+
+func parseBindArgs(args string) (BindAction, error) {
+	values, err := splitArgs(args)
+	if err != nil {
+		return BindAction{}, errors.New("\\bind: " + err.Error())
+	}
+	return BindAction{Values: values}, nil
+}
+
+// Bind sets parameter values for the next query, which is then sent with
+// the extended protocol even if it has no parameters.
+func (c *Client) Bind(values []string) {
+	c.bindValues = values
+	c.bindSet = true
+}
+
+// HasBind reports whether \bind values are waiting for the next query.
+func (c *Client) HasBind() bool {
+	return c.bindSet
+}
+
+// takeBindArgs returns the pending \bind values as query arguments and
+// clears them. The server infers the parameter types, as it does for psql.
+func (c *Client) takeBindArgs() []any {
+	if !c.bindSet {
+		return nil
+	}
+	args := make([]any, 0, len(c.bindValues)+1)
+	args = append(args, pgx.QueryExecModeDescribeExec)
+	for _, v := range c.bindValues {
+		args = append(args, v)
+	}
+	c.bindValues, c.bindSet = nil, false
+	return args
+}
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitArgs(t *testing.T) {
+	testCases := []struct {
+		name    string
+		args    string
+		want    []string
+		wantErr string
+	}{
+		{name: "empty", args: "  ", want: nil},
+		{name: "bare words", args: "1 abc  2024-01-01", want: []string{"1", "abc", "2024-01-01"}},
+		{name: "quoted whitespace", args: "'hello world' x", want: []string{"hello world", "x"}},
+		{name: "escaped quote", args: "'it''s'", want: []string{"it's"}},
+		{name: "empty quoted", args: "'' a", want: []string{"", "a"}},
+		{name: "unterminated", args: "'abc", wantErr: "unterminated quoted string"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := splitArgs(tc.args)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestClientTakeBindArgs(t *testing.T) {
+	client := &Client{}
+	assert.False(t, client.HasBind())
+	assert.Nil(t, client.takeBindArgs())
+
+	client.Bind([]string{"42", "x"})
+	assert.True(t, client.HasBind())
+	assert.Equal(t, []any{pgx.QueryExecModeDescribeExec, "42", "x"}, client.takeBindArgs())
+
+	assert.False(t, client.HasBind(), "bind values apply to one query")
+	assert.Nil(t, client.takeBindArgs())
+
+	client.Bind(nil)
+	assert.Equal(t, []any{pgx.QueryExecModeDescribeExec}, client.takeBindArgs())
+}
@@ -7,9 +7,11 @@ import (
 	"log/slog"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/balaji01-4d/pgxcli/internal/database/result"
+	"github.com/balaji01-4d/pgxcli/internal/parser"
 	"github.com/balaji01-4d/pgxspecial"
 )
 
@@ -20,6 +22,40 @@ type Client struct {
 	currentDB string
 	executor  *executor
 
+	// autocommit false issues an implicit BEGIN before the first statement
+	// run outside a transaction block, like psql's AUTOCOMMIT off.
+	autocommit bool
+
+	// onErrorRollback wraps statements run inside a transaction block in a
+	// savepoint, so a failure only undoes the failed statement.
+	onErrorRollback bool
+
+	// settings holds session SET statements by parameter name, replayed in
+	// settingOrder after a reconnect.
+	settings     map[string]string
+	settingOrder []string
+	// txSettings are the SET statements of the open transaction, kept in
+	// settings only once it commits.
+	txSettings []string
+
+	awaitingPassword bool
+
+	// channels are the LISTEN channels of the session, re-subscribed after
+	// a reconnect.
+	channels []string
+	listener *listener
+	// pending holds the notifications received since they were last passed
+	// on, such as those arriving while a statement runs.
+	pendingMu sync.Mutex
+	pending   []Notification
+
+	// prepared are the statements prepared with \parse on this connection.
+	prepared []string
+
+	// bindValues are the \bind parameters for the next query.
+	bindValues []string
+	bindSet    bool
+
 	now time.Time
 
 	logger *slog.Logger
@@ -28,14 +64,16 @@ type Client struct {
 // New creates a database client with logger-backed connection lifecycle reporting.
 func New(logger *slog.Logger) *Client {
 	postgres := &Client{
-		now:    time.Now(),
-		logger: logger,
+		now:        time.Now(),
+		autocommit: true,
+		logger:     logger,
 	}
 	return postgres
 }
 
 // Connect opens a database connection using the provided connector.
 func (c *Client) Connect(ctx context.Context, connector Connector) error {
+	connector.SetNotificationHandler(c.queueNotification)
 	exec, err := newExecutor(ctx, connector, c.logger)
 	if err != nil {
 		return err
@@ -49,12 +87,102 @@ func (c *Client) Connect(ctx context.Context, connector Connector) error {
 
 // ExecuteSpecial executes a pgxspecial command (for example: \q, \c, \conninfo).
 func (c *Client) ExecuteSpecial(ctx context.Context, command string) (pgxspecial.SpecialCommandResult, bool, error) {
-	return c.executor.executeSpecial(ctx, command)
+	res, ok, err := c.executor.executeSpecial(ctx, command)
+	if err != nil {
+		return nil, ok, c.RecoverConnection(ctx, err)
+	}
+	return res, ok, nil
 }
 
 // ExecuteQuery runs SQL through the underlying executor and returns typed results.
+// Pending \bind values are passed as the query's parameters.
 func (c *Client) ExecuteQuery(ctx context.Context, query string) (result.Result, error) {
-	return c.executor.execute(ctx, query)
+	args := c.takeBindArgs()
+	c.settleSettings(false)
+	transactional := !parser.CommandNoBegin(query)
+	if !c.autocommit && transactional && c.Status().TxStatus == TxIdle {
+		if err := c.executor.begin(ctx); err != nil {
+			return nil, c.RecoverConnection(ctx, err)
+		}
+	}
+
+	var res result.Result
+	var err error
+	if c.onErrorRollback && transactional && c.Status().TxStatus == TxActive {
+		res, err = c.executor.executeWithSavepoint(ctx, query, args...)
+	} else {
+		res, err = c.executor.execute(ctx, query, args...)
+	}
+	if err != nil {
+		c.settleSettings(false)
+		return nil, c.RecoverConnection(ctx, err)
+	}
+	if err := c.recordSetting(query, res); err != nil {
+		c.settleSettings(false)
+		return nil, c.RecoverConnection(ctx, err)
+	}
+	return res, nil
+}
+
+// recordSetting keeps a SET statement for reconnects once its result was
+// read without error, as a failing SET only reports its error then. A SET
+// inside a transaction block waits for the transaction to commit, which a
+// statement ending the block is read for.
+func (c *Client) recordSetting(query string, res result.Result) error {
+	_, isSetting := parser.ParseSettingChange(query)
+	endsTx := len(c.txSettings) > 0 && parser.EndsTransaction(query)
+	if !isSetting && !endsTx {
+		return nil
+	}
+
+	var commandTag string
+	if queryResult, ok := res.(*result.QueryResult); ok {
+		if err := queryResult.Materialize(); err != nil {
+			return err
+		}
+		commandTag = queryResult.CommandTag()
+	}
+	status := c.Status().TxStatus
+	switch {
+	case endsTx:
+		// COMMIT of a failed transaction reports ROLLBACK
+		c.settleSettings(commandTag == "COMMIT")
+	case status == TxActive || status == TxFailed:
+		c.txSettings = append(c.txSettings, query)
+	default:
+		c.trackSetting(query)
+	}
+	return nil
+}
+
+// settleSettings keeps the SET statements of a transaction that ended, when
+// it committed, or else forgets them with the rest of the transaction.
+func (c *Client) settleSettings(committed bool) {
+	if len(c.txSettings) == 0 || c.Status().TxStatus != TxIdle {
+		return
+	}
+	if committed {
+		for _, stmt := range c.txSettings {
+			c.trackSetting(stmt)
+		}
+	}
+	c.txSettings = nil
+}
+
+// SetOnErrorRollback enables or disables per-statement savepoint recovery
+// inside transaction blocks.
+func (c *Client) SetOnErrorRollback(enabled bool) {
+	c.onErrorRollback = enabled
+}
+
+// SetAutocommit enables or disables autocommit mode.
+func (c *Client) SetAutocommit(autocommit bool) {
+	c.autocommit = autocommit
+}
+
+// Autocommit reports whether autocommit mode is enabled.
+func (c *Client) Autocommit() bool {
+	return c.autocommit
 }
 
 // IsConnected reports whether the client currently has an active connection.
@@ -90,6 +218,9 @@ func (c *Client) ChangeDatabase(ctx context.Context, dbName string) error {
 
 	c.executor = exec
 	c.currentDB = exec.Database
+	c.clearSettings()
+	c.channels = nil
+	c.prepared = nil
 
 	if oldExecutor != nil {
 		if err := oldExecutor.close(ctx); err != nil {
@@ -103,9 +234,17 @@ func (c *Client) ChangeDatabase(ctx context.Context, dbName string) error {
 	return nil
 }
 
+// Status returns the live session state of the current connection.
+func (c *Client) Status() SessionStatus {
+	if c.executor == nil {
+		return SessionStatus{}
+	}
+	return c.executor.status()
+}
+
 // ParsePrompt resolves prompt placeholders using current connection metadata.
 func (c *Client) ParsePrompt(str string) string {
-	var user, host, shortHost, db, port string
+	var user, host, shortHost, db, port, pid, version string
 
 	t := c.now.Format("02/06/2006 15:04:05")
 
@@ -143,6 +282,28 @@ func (c *Client) ParsePrompt(str string) string {
 		}
 	}
 
+	status := c.Status()
+	if status.PID != 0 {
+		pid = strconv.FormatUint(uint64(status.PID), 10)
+	} else {
+		pid = nilPlaceholder
+	}
+	if status.ServerVersion != "" {
+		version = status.ServerVersion
+	} else {
+		version = nilPlaceholder
+	}
+
+	superuser := ">"
+	if status.Superuser {
+		superuser = "#"
+	}
+
+	var readOnly string
+	if status.ReadOnly {
+		readOnly = "(ro)"
+	}
+
 	return strings.NewReplacer(
 		`\t`, t,
 		`\u`, user,
@@ -150,6 +311,11 @@ func (c *Client) ParsePrompt(str string) string {
 		`\h`, shortHost,
 		`\d`, db,
 		`\p`, port,
+		`\i`, pid,
+		`\V`, version,
+		`\x`, status.TxStatus.Marker(),
+		`\#`, superuser,
+		`\r`, readOnly,
 		`\n`, "\n",
 	).Replace(str)
 }
@@ -186,6 +352,15 @@ func (c *Client) GetHost() string {
 	return c.executor.Host
 }
 
+// GetHostAddr returns the IP address of the current connection, empty for a
+// unix socket.
+func (c *Client) GetHostAddr() string {
+	if c.executor == nil {
+		return ""
+	}
+	return c.executor.Addr
+}
+
 // Ping verifies connectivity to the current database.
 func (c *Client) Ping(ctx context.Context) error {
 	if !c.IsConnected() {
@@ -195,8 +370,13 @@ func (c *Client) Ping(ctx context.Context) error {
 }
 
 // Close closes the current database connection if one exists.
+// The server rolls back any transaction still open on the connection.
 func (c *Client) Close(ctx context.Context) error {
+	c.StopListening()
 	if c.executor != nil {
+		if status := c.Status().TxStatus; status == TxActive || status == TxFailed {
+			c.logger.Warn("Closing connection with an open transaction, it will be rolled back", "status", status.String())
+		}
 		return c.executor.close(ctx)
 	}
 	return nil
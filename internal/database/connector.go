@@ -3,9 +3,13 @@ package database
 import (
 	"context"
 	"net"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgconn/ctxwatch"
 )
 
 // Connector describes how the client obtains and updates a database connection.
@@ -13,6 +17,12 @@ type Connector interface {
 	Connect(ctx context.Context) (*pgx.Conn, error)
 	UpdatePassword(password string)
 	Password() string
+	// SetDialFunc replaces how connections to the server are opened, e.g.
+	// to go through an SSH tunnel.
+	SetDialFunc(dial pgconn.DialFunc)
+	// SetNotificationHandler sets what is called with each notification
+	// received on the connections, whatever they are doing at the time.
+	SetNotificationHandler(handler pgconn.NotificationHandler)
 }
 
 // pgConnector holds pgx connection configuration and creates database connections.
@@ -27,30 +37,146 @@ func NewPGConnectorFromConnString(connString string) (Connector, error) {
 		return nil, err
 	}
 
-	return &pgConnector{cfg: cfg}, nil
+	return newPGConnector(cfg), nil
 }
 
-// NewPGConnectorFromFields builds a connector from individual connection fields.
-func NewPGConnectorFromFields(host, database, user, password string, port uint16) (Connector, error) {
-	cfg, err := pgx.ParseConfig("")
-	if err != nil {
-		return nil, err
+// cancelDeadline is how long a statement canceled on the server may take to
+// stop before its connection is given up.
+const cancelDeadline = 5 * time.Second
+
+// cancelOnServerKey marks a context whose cancellation is sent to the server.
+type cancelOnServerKey struct{}
+
+// CancelOnServer returns a context whose cancellation cancels the running
+// statement on the server, so the connection stays usable, as for a \watch
+// stopped with ctrl+c. Other contexts interrupt the connection at once when
+// canceled, which is what stopping the wait for notifications needs: an
+// idle backend ignores a cancel request.
+func CancelOnServer(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cancelOnServerKey{}, true)
+}
+
+// contextWatcher handles the cancellation of a statement's context, by a
+// cancel request for contexts from CancelOnServer and by a read deadline
+// otherwise.
+type contextWatcher struct {
+	cancelRequest ctxwatch.Handler
+	deadline      ctxwatch.Handler
+	// canceled is the handler of the context canceled last.
+	canceled ctxwatch.Handler
+}
+
+func (w *contextWatcher) HandleCancel(ctx context.Context) {
+	w.canceled = w.deadline
+	if ctx.Value(cancelOnServerKey{}) != nil {
+		w.canceled = w.cancelRequest
+	}
+	w.canceled.HandleCancel(ctx)
+}
+
+func (w *contextWatcher) HandleUnwatchAfterCancel() {
+	w.canceled.HandleUnwatchAfterCancel()
+}
+
+// newPGConnector sets up cfg for pgxcli: statements run in exec mode, and
+// connections are dialed with a timeout. Contexts from CancelOnServer cancel
+// their statement on the server when canceled. Settings made here carry over
+// to the copies of the config used by \c and reconnects.
+func newPGConnector(cfg *pgx.ConnConfig) *pgConnector {
+	cfg.DefaultQueryExecMode = pgx.QueryExecModeExec
+
+	dialer := &net.Dialer{}
+	dialer.Timeout = 5 * time.Second
+	if cfg.ConnectTimeout > 0 {
+		dialer.Timeout = cfg.ConnectTimeout
 	}
+	cfg.DialFunc = dialer.DialContext
+	cfg.BuildContextWatcherHandler = func(conn *pgconn.PgConn) ctxwatch.Handler {
+		return &contextWatcher{
+			cancelRequest: &pgconn.CancelRequestContextWatcherHandler{Conn: conn, DeadlineDelay: cancelDeadline},
+			deadline:      &pgconn.DeadlineContextWatcherHandler{Conn: conn.Conn()},
+		}
+	}
+	return &pgConnector{cfg: cfg}
+}
 
-	checkAndSet := func(field *string, value string) {
+// ConnFields are the settings of a field-based connection. Empty fields fall
+// back to the service, the libpq environment variables and defaults, and an
+// empty password is looked up in the password file (~/.pgpass or PGPASSFILE)
+// for the resulting host, port, database and user.
+type ConnFields struct {
+	// Service names an entry of the connection service file
+	// (~/.pg_service.conf or PGSERVICEFILE) whose settings apply where the
+	// other fields are empty.
+	Service  string
+	Host     string
+	Port     uint16
+	Database string
+	User     string
+	Password string
+	// SSLMode is a libpq sslmode, such as "require" or "verify-full".
+	SSLMode string
+	// SSLRootCert is the file of the CA certificates the server certificate
+	// is verified against.
+	SSLRootCert string
+	// SSLCert and SSLKey are the files of the client certificate and its key.
+	SSLCert string
+	SSLKey  string
+	// SSLPassword decrypts SSLKey. When it is empty or wrong and the key is
+	// encrypted, GetSSLPassword is asked for it.
+	SSLPassword    string
+	GetSSLPassword func(ctx context.Context) string
+	// TargetSessionAttrs picks which of several hosts to settle on, e.g.
+	// "read-write" for the primary.
+	TargetSessionAttrs string
+	// Options are command-line options sent to the server at connection
+	// start, e.g. "-c search_path=app".
+	Options string
+}
+
+// connString renders the fields as a keyword/value connection string, so
+// that settings depending on each other, such as the TLS server name and the
+// host, are resolved together.
+func (f ConnFields) connString() string {
+	var settings []string
+	add := func(key, value string) {
 		if value != "" {
-			*field = value
+			settings = append(settings, connSetting(key, value))
 		}
 	}
-	checkAndSet(&cfg.Host, host)
-	checkAndSet(&cfg.Database, database)
-	checkAndSet(&cfg.User, user)
-	checkAndSet(&cfg.Password, password)
+	add("service", f.Service)
+	add("host", f.Host)
+	if f.Port != 0 {
+		add("port", strconv.Itoa(int(f.Port)))
+	}
+	add("dbname", f.Database)
+	add("user", f.User)
+	add("password", f.Password)
+	add("sslmode", f.SSLMode)
+	add("sslrootcert", f.SSLRootCert)
+	add("sslcert", f.SSLCert)
+	add("sslkey", f.SSLKey)
+	add("sslpassword", f.SSLPassword)
+	add("target_session_attrs", f.TargetSessionAttrs)
+	add("options", f.Options)
+	return strings.Join(settings, " ")
+}
+
+// connSetting renders a keyword/value connection string setting, quoting
+// value.
+func connSetting(key, value string) string {
+	return key + "='" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
 
-	if port != 0 {
-		cfg.Port = port
+// NewPGConnectorFromFields builds a connector from individual connection fields.
+func NewPGConnectorFromFields(fields ConnFields) (Connector, error) {
+	cfg, err := pgx.ParseConfigWithOptions(fields.connString(), pgx.ParseConfigOptions{
+		ParseConfigOptions: pgconn.ParseConfigOptions{GetSSLPassword: fields.GetSSLPassword},
+	})
+	if err != nil {
+		return nil, err
 	}
-	return &pgConnector{cfg: cfg}, nil
+	return newPGConnector(cfg), nil
 }
 
 // UpdatePassword updates the password on the underlying connection config.
@@ -63,17 +189,23 @@ func (c *pgConnector) Password() string {
 	return c.cfg.Password
 }
 
-// Connect opens a new pgx connection using the connector configuration.
-func (c *pgConnector) Connect(ctx context.Context) (*pgx.Conn, error) {
-	c.cfg.DefaultQueryExecMode = pgx.QueryExecModeExec
-
-	dialer := &net.Dialer{}
-	dialer.Timeout = 5 * time.Second
-	if c.cfg.ConnectTimeout > 0 {
-		dialer.Timeout = c.cfg.ConnectTimeout
+// SetDialFunc replaces the dialer set up by the constructor. Host names are
+// then passed to dial unresolved, since a tunnel resolves them at its far end.
+func (c *pgConnector) SetDialFunc(dial pgconn.DialFunc) {
+	c.cfg.DialFunc = dial
+	c.cfg.LookupFunc = func(_ context.Context, host string) ([]string, error) {
+		return []string{host}, nil
 	}
-	c.cfg.DialFunc = dialer.DialContext
+}
 
+// SetNotificationHandler replaces pgx's own buffering of notifications,
+// which only hands them out from WaitForNotification.
+func (c *pgConnector) SetNotificationHandler(handler pgconn.NotificationHandler) {
+	c.cfg.OnNotification = handler
+}
+
+// Connect opens a new pgx connection using the connector configuration.
+func (c *pgConnector) Connect(ctx context.Context) (*pgx.Conn, error) {
 	conn, err := pgx.ConnectConfig(ctx, c.cfg)
 	if err != nil {
 		return nil, err
@@ -1,9 +1,24 @@
 package database
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPGConnector_UpdatePassword_ConnString(t *testing.T) {
@@ -82,7 +97,13 @@ func TestPGConnector_UpdatePassword_Fields(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c, err := NewPGConnectorFromFields(tt.host, tt.database, tt.user, tt.password, tt.port)
+			c, err := NewPGConnectorFromFields(ConnFields{
+				Host:     tt.host,
+				Port:     tt.port,
+				Database: tt.database,
+				User:     tt.user,
+				Password: tt.password,
+			})
 			assert.NoError(t, err)
 			c.UpdatePassword(tt.newPassword)
 
@@ -90,3 +111,192 @@ func TestPGConnector_UpdatePassword_Fields(t *testing.T) {
 		})
 	}
 }
+
+func TestPGConnector_Fields(t *testing.T) {
+	c, err := NewPGConnectorFromFields(ConnFields{
+		Host:     "db.internal",
+		Port:     6432,
+		Database: "app",
+		User:     "o'brien",
+		SSLMode:  "require",
+		Options:  `-c search_path=app\,public`,
+	})
+	require.NoError(t, err)
+
+	cfg := c.(*pgConnector).cfg
+	assert.Equal(t, "db.internal", cfg.Host)
+	assert.Equal(t, uint16(6432), cfg.Port)
+	assert.Equal(t, "app", cfg.Database)
+	assert.Equal(t, "o'brien", cfg.User)
+	assert.Equal(t, `-c search_path=app\,public`, cfg.RuntimeParams["options"])
+	require.NotNil(t, cfg.TLSConfig, "sslmode=require uses TLS")
+	assert.Empty(t, cfg.Fallbacks, "sslmode=require has no plaintext fallback")
+
+	_, err = NewPGConnectorFromFields(ConnFields{Host: "localhost", SSLMode: "sometimes"})
+	assert.Error(t, err)
+}
+
+func TestPGConnector_FieldsServiceAndPassfile(t *testing.T) {
+	dir := t.TempDir()
+	serviceFile := filepath.Join(dir, "pg_service.conf")
+	require.NoError(t, os.WriteFile(serviceFile, []byte(
+		"[analytics]\nhost=warehouse.internal\nport=6432\ndbname=events\nuser=reader\n",
+	), 0o600))
+	passFile := filepath.Join(dir, "pgpass")
+	require.NoError(t, os.WriteFile(passFile, []byte(
+		"warehouse.internal:6432:events:reader:from-pgpass\n"+
+			"warehouse.internal:6432:archive:reader:archive-secret\n",
+	), 0o600))
+	t.Setenv("PGSERVICEFILE", serviceFile)
+	t.Setenv("PGPASSFILE", passFile)
+	t.Setenv("PGPASSWORD", "")
+
+	tests := []struct {
+		name         string
+		fields       ConnFields
+		wantDatabase string
+		wantPassword string
+	}{
+		{
+			name:         "service settings",
+			fields:       ConnFields{Service: "analytics"},
+			wantDatabase: "events",
+			wantPassword: "from-pgpass",
+		},
+		{
+			name:         "password looked up after overrides",
+			fields:       ConnFields{Service: "analytics", Database: "archive"},
+			wantDatabase: "archive",
+			wantPassword: "archive-secret",
+		},
+		{
+			name:         "given password wins",
+			fields:       ConnFields{Service: "analytics", Password: "typed"},
+			wantDatabase: "events",
+			wantPassword: "typed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewPGConnectorFromFields(tt.fields)
+			require.NoError(t, err)
+
+			cfg := c.(*pgConnector).cfg
+			assert.Equal(t, "warehouse.internal", cfg.Host)
+			assert.Equal(t, uint16(6432), cfg.Port)
+			assert.Equal(t, "reader", cfg.User)
+			assert.Equal(t, tt.wantDatabase, cfg.Database)
+			assert.Equal(t, tt.wantPassword, c.Password())
+		})
+	}
+
+	_, err := NewPGConnectorFromFields(ConnFields{Service: "missing"})
+	assert.Error(t, err)
+}
+
+// writeClientCert writes a self-signed certificate and its RSA key, encrypted
+// with passphrase, and returns their paths.
+func writeClientCert(t *testing.T, passphrase string) (string, string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "app"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	//nolint:staticcheck // legacy PEM encryption is the only kind sslpassword supports
+	block, err := x509.EncryptPEMBlock(rand.Reader, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key), []byte(passphrase), x509.PEMCipherAES256)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "client.crt")
+	keyPath := filepath.Join(dir, "client.key")
+	require.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(block), 0o600))
+	return certPath, keyPath
+}
+
+func TestPGConnector_FieldsEncryptedKey(t *testing.T) {
+	certPath, keyPath := writeClientCert(t, "s3cret")
+	fields := ConnFields{Host: "db.internal", SSLMode: "require", SSLCert: certPath, SSLKey: keyPath}
+
+	var asked int
+	fields.GetSSLPassword = func(context.Context) string {
+		asked++
+		return "s3cret"
+	}
+	c, err := NewPGConnectorFromFields(fields)
+	require.NoError(t, err)
+	assert.Equal(t, 1, asked, "the passphrase is asked for once")
+	assert.Len(t, c.(*pgConnector).cfg.TLSConfig.Certificates, 1)
+
+	fields.SSLPassword = "s3cret"
+	fields.GetSSLPassword = func(context.Context) string {
+		t.Error("the given sslpassword is used without asking")
+		return ""
+	}
+	_, err = NewPGConnectorFromFields(fields)
+	require.NoError(t, err)
+
+	fields.SSLPassword = ""
+	fields.GetSSLPassword = nil
+	_, err = NewPGConnectorFromFields(fields)
+	assert.ErrorContains(t, err, "sslpassword")
+}
+
+func TestPGConnector_SetDialFunc(t *testing.T) {
+	c, err := NewPGConnectorFromConnString("postgres://app@db.internal:5432/app?connect_timeout=1")
+	require.NoError(t, err)
+
+	var dialed []string
+	c.SetDialFunc(func(_ context.Context, network, addr string) (net.Conn, error) {
+		dialed = append(dialed, network+" "+addr)
+		return nil, errors.New("no route through the tunnel")
+	})
+
+	_, err = c.Connect(context.Background())
+	assert.ErrorContains(t, err, "no route through the tunnel")
+	assert.Contains(t, dialed, "tcp db.internal:5432", "the host is resolved at the far end of the tunnel")
+
+	copied := c.(*pgConnector).cfg.Copy()
+	_, err = (&pgConnector{cfg: copied}).Connect(context.Background())
+	assert.ErrorContains(t, err, "no route through the tunnel", "copies used by \\c and reconnects keep the dialer")
+}
+
+func TestPGConnector_CancelsStatementsOnServer(t *testing.T) {
+	queried := make(chan struct{}, 1)
+	var server *fakeServer
+	server = startFakeServer(t, func(msg pgproto3.FrontendMessage) []pgproto3.BackendMessage {
+		if q, ok := msg.(*pgproto3.Query); ok && q.String == "SELECT pg_sleep(60)" {
+			queried <- struct{}{}
+			select {
+			case <-server.cancels:
+				return []pgproto3.BackendMessage{
+					&pgproto3.ErrorResponse{Severity: "ERROR", Code: "57014", Message: "canceling statement due to user request"},
+					&pgproto3.ReadyForQuery{TxStatus: 'I'},
+				}
+			case <-time.After(time.Second):
+				return nil
+			}
+		}
+		return pingReplies(msg)
+	})
+	client := server.connect(t)
+
+	ctx, cancel := context.WithCancel(CancelOnServer(t.Context()))
+	go func() {
+		<-queried
+		cancel()
+	}()
+	_, err := client.executor.Conn.Exec(ctx, "SELECT pg_sleep(60)")
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, err, &pgErr, "the statement is canceled by the server")
+	assert.Equal(t, "57014", pgErr.Code)
+	assert.NoError(t, client.Ping(t.Context()))
+}
@@ -0,0 +1,387 @@
+package database
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/balaji01-4d/pgxspecial"
+)
+
+// copyProgressInterval throttles progress callbacks during \copy.
+const copyProgressInterval = 200 * time.Millisecond
+
+// CopyAction describes a client-side \copy between a table (or query) and a
+// local file, streamed over the COPY protocol.
+type CopyAction struct {
+	// Source is the table with an optional column list, or a parenthesized
+	// query when copying to a file.
+	Source string
+	// From is true for "from" (load into the table) and false for "to".
+	From bool
+	// Path is the local file name; empty means stdin or stdout.
+	Path string
+	// Options is passed to COPY verbatim, e.g. "with (format csv, header)".
+	Options string
+}
+
+// ResultKind returns the special result kind for CopyAction.
+func (a CopyAction) ResultKind() pgxspecial.SpecialResultKind {
+	return Copy
+}
+
+// Stdio reports whether the copy reads from stdin or writes to stdout.
+func (a CopyAction) Stdio() bool {
+	return a.Path == ""
+}
+
+// Statement returns the server-side COPY statement for the action.
+func (a CopyAction) Statement() string {
+	direction := "TO STDOUT"
+	if a.From {
+		direction = "FROM STDIN"
+	}
+	stmt := "COPY " + a.Source + " " + direction
+	if a.Options != "" {
+		stmt += " " + a.Options
+	}
+	return stmt
+}
+
+// CopyProgress reports how much data a \copy has transferred so far.
+type CopyProgress struct {
+	Rows  int64
+	Bytes int64
+}
+
+func (p CopyProgress) String() string {
+	return fmt.Sprintf("%d rows, %s", p.Rows, FormatBytes(p.Bytes))
+}
+
+// FormatBytes renders a byte count with binary units, e.g. "1.5 KiB".
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// parseCopyArgs parses psql's \copy syntax:
+//
+//	table [(cols)] from|to 'file'|stdin|stdout [[with] (options)]
+//	(query) to 'file'|stdout [[with] (options)]
+func parseCopyArgs(args string) (CopyAction, error) {
+	tokens, err := copyTokens(args)
+	if err != nil {
+		return CopyAction{}, err
+	}
+	if len(tokens) == 0 {
+		return CopyAction{}, errors.New("\\copy: arguments required")
+	}
+
+	dir := -1
+	for i, tok := range tokens {
+		if lower := strings.ToLower(tok); lower == "from" || lower == "to" {
+			dir = i
+			break
+		}
+	}
+	if dir <= 0 || dir == len(tokens)-1 {
+		return CopyAction{}, errors.New("\\copy: parse error at end of line")
+	}
+
+	action := CopyAction{
+		Source:  strings.Join(tokens[:dir], " "),
+		From:    strings.EqualFold(tokens[dir], "from"),
+		Options: strings.Join(tokens[dir+2:], " "),
+	}
+	if action.From && strings.HasPrefix(action.Source, "(") {
+		return CopyAction{}, errors.New("\\copy: a query can only be used with \"to\"")
+	}
+
+	if err := action.setTarget(tokens[dir+1]); err != nil {
+		return CopyAction{}, err
+	}
+	return action, nil
+}
+
+// setTarget applies the file name (or stdin/stdout keyword) of a \copy.
+func (a *CopyAction) setTarget(target string) error {
+	switch strings.ToLower(target) {
+	case "stdin", "pstdin":
+		if !a.From {
+			return fmt.Errorf("\\copy: cannot copy to %s", target)
+		}
+	case "stdout", "pstdout":
+		if a.From {
+			return fmt.Errorf("\\copy: cannot copy from %s", target)
+		}
+	case "program":
+		return errors.New("\\copy: program is not supported")
+	default:
+		if strings.HasPrefix(target, "'") {
+			target = strings.ReplaceAll(target[1:len(target)-1], "''", "'")
+		}
+		if target == "" {
+			return errors.New("\\copy: file name must not be empty")
+		}
+		a.Path = target
+	}
+	return nil
+}
+
+// copyTokens splits args into words, keeping quoted strings and
+// parenthesized groups intact.
+func copyTokens(args string) ([]string, error) {
+	var t copyTokenizer
+	for _, r := range args {
+		if err := t.feed(r); err != nil {
+			return nil, err
+		}
+	}
+
+	if t.quote != 0 {
+		return nil, errors.New("\\copy: unterminated quoted string")
+	}
+	if t.depth != 0 {
+		return nil, errors.New("\\copy: unbalanced parentheses")
+	}
+	t.flush()
+	return t.tokens, nil
+}
+
+type copyTokenizer struct {
+	tokens []string
+	cur    strings.Builder
+	quote  rune
+	depth  int
+}
+
+func (t *copyTokenizer) feed(r rune) error {
+	switch {
+	case t.quote != 0:
+		if r == t.quote {
+			t.quote = 0
+		}
+	case r == '\'' || r == '"':
+		t.quote = r
+	case r == '(':
+		if t.depth == 0 {
+			t.flush()
+		}
+		t.depth++
+	case r == ')':
+		t.depth--
+		if t.depth < 0 {
+			return errors.New("\\copy: unbalanced parentheses")
+		}
+		if t.depth == 0 {
+			t.cur.WriteRune(r)
+			t.flush()
+			return nil
+		}
+	case t.depth == 0 && (r == ' ' || r == '\t' || r == '\n'):
+		t.flush()
+		return nil
+	}
+	t.cur.WriteRune(r)
+	return nil
+}
+
+func (t *copyTokenizer) flush() {
+	if t.cur.Len() > 0 {
+		t.tokens = append(t.tokens, t.cur.String())
+		t.cur.Reset()
+	}
+}
+
+// Copy runs a client-side \copy, streaming between the local file (or
+// stdin/stdout) and the server. progress, when non-nil, is called
+// periodically while data is transferred.
+func (c *Client) Copy(ctx context.Context, action CopyAction, stdin io.Reader, stdout io.Writer, progress func(CopyProgress)) (CopyProgress, error) {
+	if c.executor == nil {
+		return CopyProgress{}, errors.New("not connected to any database")
+	}
+	pgConn := c.executor.Conn.PgConn()
+	if pgConn == nil {
+		return CopyProgress{}, errors.New("\\copy: connection does not support the COPY protocol")
+	}
+
+	counter := &copyCounter{progress: progress, last: time.Now()}
+	stmt := action.Statement()
+	c.logger.Debug("Running client-side copy", "sql", stmt, "path", action.Path)
+
+	var err error
+	if action.From {
+		err = c.copyFrom(ctx, action, stdin, counter)
+	} else {
+		err = c.copyTo(ctx, action, stdout, counter)
+	}
+	if err != nil {
+		return counter.snapshot(), c.RecoverConnection(ctx, err)
+	}
+	return counter.snapshot(), nil
+}
+
+func (c *Client) copyFrom(ctx context.Context, action CopyAction, stdin io.Reader, counter *copyCounter) error {
+	var src io.Reader
+	if action.Stdio() {
+		src = newCopyDataReader(stdin)
+	} else {
+		f, err := os.Open(expandHome(action.Path))
+		if err != nil {
+			return fmt.Errorf("\\copy: %w", err)
+		}
+		defer f.Close()
+		src = f
+
+		if isGzipPath(action.Path) {
+			zr, err := gzip.NewReader(f)
+			if err != nil {
+				return fmt.Errorf("\\copy: %w", err)
+			}
+			defer zr.Close()
+			src = zr
+		}
+	}
+
+	tag, err := c.executor.Conn.PgConn().CopyFrom(ctx, &countingReader{r: src, counter: counter}, action.Statement())
+	if err != nil {
+		return err
+	}
+	counter.rows = tag.RowsAffected()
+	return nil
+}
+
+func (c *Client) copyTo(ctx context.Context, action CopyAction, stdout io.Writer, counter *copyCounter) (err error) {
+	dst := stdout
+	if !action.Stdio() {
+		f, createErr := os.Create(expandHome(action.Path))
+		if createErr != nil {
+			return fmt.Errorf("\\copy: %w", createErr)
+		}
+		defer func() { err = errors.Join(err, f.Close()) }()
+		dst = f
+
+		if isGzipPath(action.Path) {
+			zw := gzip.NewWriter(f)
+			defer func() { err = errors.Join(err, zw.Close()) }()
+			dst = zw
+		}
+	}
+
+	tag, err := c.executor.Conn.PgConn().CopyTo(ctx, &countingWriter{w: dst, counter: counter}, action.Statement())
+	if err != nil {
+		return err
+	}
+	counter.rows = tag.RowsAffected()
+	return nil
+}
+
+func isGzipPath(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".gz")
+}
+
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
+
+// copyCounter tracks transferred bytes and lines. Lines approximate rows
+// until the server reports the final count.
+type copyCounter struct {
+	rows, bytes int64
+	progress    func(CopyProgress)
+	last        time.Time
+}
+
+func (c *copyCounter) add(p []byte) {
+	c.bytes += int64(len(p))
+	c.rows += int64(bytes.Count(p, []byte{'\n'}))
+	if c.progress != nil && time.Since(c.last) >= copyProgressInterval {
+		c.last = time.Now()
+		c.progress(c.snapshot())
+	}
+}
+
+func (c *copyCounter) snapshot() CopyProgress {
+	return CopyProgress{Rows: c.rows, Bytes: c.bytes}
+}
+
+type countingReader struct {
+	r       io.Reader
+	counter *copyCounter
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.counter.add(p[:n])
+	return n, err
+}
+
+type countingWriter struct {
+	w       io.Writer
+	counter *copyCounter
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.counter.add(p[:n])
+	return n, err
+}
+
+// copyDataReader reads COPY data typed on stdin, stopping at EOF or at a
+// line containing only "\.", like psql.
+type copyDataReader struct {
+	r       *bufio.Reader
+	pending []byte
+	done    bool
+}
+
+func newCopyDataReader(r io.Reader) *copyDataReader {
+	return &copyDataReader{r: bufio.NewReader(r)}
+}
+
+func (d *copyDataReader) Read(p []byte) (int, error) {
+	for len(d.pending) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		line, err := d.r.ReadBytes('\n')
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				return 0, err
+			}
+			d.done = true
+		}
+		if string(bytes.TrimRight(line, "\r\n")) == `\.` {
+			d.done = true
+			continue
+		}
+		d.pending = line
+	}
+
+	n := copy(p, d.pending)
+	d.pending = d.pending[n:]
+	return n, nil
+}
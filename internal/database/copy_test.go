@@ -0,0 +1,114 @@
+package database
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCopyArgs(t *testing.T) {
+	testCases := []struct {
+		name     string
+		args     string
+		want     CopyAction
+		wantStmt string
+		wantErr  string
+	}{
+		{
+			name:     "from quoted file",
+			args:     "users from '/tmp/users.csv' with (format csv, header)",
+			want:     CopyAction{Source: "users", From: true, Path: "/tmp/users.csv", Options: "with (format csv, header)"},
+			wantStmt: "COPY users FROM STDIN with (format csv, header)",
+		},
+		{
+			name:     "column list and bare file",
+			args:     "users (id, name) TO out.tsv",
+			want:     CopyAction{Source: "users (id, name)", Path: "out.tsv"},
+			wantStmt: "COPY users (id, name) TO STDOUT",
+		},
+		{
+			name:     "query to stdout",
+			args:     "(select * from t where a = 'to') to stdout csv",
+			want:     CopyAction{Source: "(select * from t where a = 'to')", Options: "csv"},
+			wantStmt: "COPY (select * from t where a = 'to') TO STDOUT csv",
+		},
+		{
+			name:     "from stdin",
+			args:     "\"My Table\" from stdin",
+			want:     CopyAction{Source: "\"My Table\"", From: true},
+			wantStmt: "COPY \"My Table\" FROM STDIN",
+		},
+		{
+			name: "escaped quote in file name",
+			args: "t to 'it''s.csv.gz'",
+			want: CopyAction{Source: "t", Path: "it's.csv.gz"},
+		},
+		{name: "empty", args: "", wantErr: "arguments required"},
+		{name: "missing file", args: "t from", wantErr: "parse error at end of line"},
+		{name: "missing direction", args: "t 'file'", wantErr: "parse error at end of line"},
+		{name: "query from file", args: "(select 1) from 'f'", wantErr: "a query can only be used"},
+		{name: "stdout as source", args: "t from stdout", wantErr: "cannot copy from stdout"},
+		{name: "program", args: "t to program 'gzip'", wantErr: "program is not supported"},
+		{name: "unterminated quote", args: "t to 'f", wantErr: "unterminated quoted string"},
+		{name: "unbalanced parens", args: "t (a from 'f'", wantErr: "unbalanced parentheses"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseCopyArgs(tc.args)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+			if tc.wantStmt != "" {
+				assert.Equal(t, tc.wantStmt, got.Statement())
+			}
+		})
+	}
+}
+
+func TestCopyDataReader(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "stops at terminator", input: "1\ta\n2\tb\n\\.\nignored\n", want: "1\ta\n2\tb\n"},
+		{name: "stops at eof", input: "1\ta\n2\tb", want: "1\ta\n2\tb"},
+		{name: "crlf terminator", input: "1\r\n\\.\r\n", want: "1\r\n"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := io.ReadAll(newCopyDataReader(strings.NewReader(tc.input)))
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, string(got))
+		})
+	}
+}
+
+func TestCountingReaderReportsProgress(t *testing.T) {
+	var reports []CopyProgress
+	counter := &copyCounter{progress: func(p CopyProgress) { reports = append(reports, p) }}
+
+	_, err := io.ReadAll(&countingReader{r: strings.NewReader("a\nb\nc\n"), counter: counter})
+	require.NoError(t, err)
+
+	assert.Equal(t, CopyProgress{Rows: 3, Bytes: 6}, counter.snapshot())
+	require.NotEmpty(t, reports)
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "512 B", FormatBytes(512))
+	assert.Equal(t, "1.5 KiB", FormatBytes(1536))
+	assert.Equal(t, "2.0 MiB", FormatBytes(2<<20))
+	assert.Equal(t, "3 rows, 6 B", CopyProgress{Rows: 3, Bytes: 6}.String())
+}
@@ -2,8 +2,10 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"time"
 
 	"github.com/balaji01-4d/pgxcli/internal/database/result"
@@ -18,13 +20,24 @@ type conn interface {
 	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
 	Config() *pgx.ConnConfig
+	PgConn() *pgconn.PgConn
+	WaitForNotification(ctx context.Context) (*pgconn.Notification, error)
+	Prepare(ctx context.Context, name, sql string) (*pgconn.StatementDescription, error)
+	Deallocate(ctx context.Context, name string) error
 	Ping(ctx context.Context) error
 	Close(ctx context.Context) error
 }
 
+// onErrorRollbackSavepoint wraps statements when on_error_rollback is enabled.
+const onErrorRollbackSavepoint = "pgxcli_on_error_rollback"
+
 type executor struct {
-	Host     string
-	Port     uint16
+	// Host and Port are those of the host actually connected to, which for
+	// a multi-host connection need not be the first one.
+	Host string
+	Port uint16
+	// Addr is the IP address connected to, empty for a unix socket.
+	Addr     string
 	Database string
 	Schema   string
 	User     string
@@ -33,6 +46,10 @@ type executor struct {
 	Conn     conn
 
 	Logger *slog.Logger
+
+	// txStatus reports the transaction status after a statement; nil means
+	// the status reported by the server on Conn.
+	txStatus func() TxStatus
 }
 
 func newExecutor(ctx context.Context, c Connector, logger *slog.Logger) (*executor, error) {
@@ -51,9 +68,16 @@ func newExecutor(ctx context.Context, c Connector, logger *slog.Logger) (*execut
 		return nil, err
 	}
 
+	var remote net.Addr
+	if netConn := conn.PgConn().Conn(); netConn != nil {
+		remote = netConn.RemoteAddr()
+	}
+	host, port := connectedHost(ctx, &conn.Config().Config, remote)
+
 	return &executor{
-		Host:     conn.Config().Host,
-		Port:     conn.Config().Port,
+		Host:     host,
+		Port:     port,
+		Addr:     remoteIP(remote),
 		Database: conn.Config().Database,
 		User:     conn.Config().User,
 		Password: conn.Config().Password,
@@ -83,6 +107,79 @@ func (e *executor) execute(ctx context.Context, sql string, args ...any) (result
 	return e.query(ctx, sql, args...)
 }
 
+// executePrepared runs the statement prepared as name. pgx resolves a
+// prepared statement name passed as the SQL to that statement and executes
+// it with its described parameter types, whatever the connection's default
+// exec mode.
+func (e *executor) executePrepared(ctx context.Context, name string, args ...any) (result.Result, error) {
+	return e.query(ctx, name, args...)
+}
+
+// executeWithSavepoint runs sql inside an implicit savepoint so a failure
+// only undoes this statement rather than aborting the whole transaction.
+// Rows are read eagerly, since errors may only surface while reading them.
+func (e *executor) executeWithSavepoint(ctx context.Context, sql string, args ...any) (result.Result, error) {
+	if _, err := e.Conn.Exec(ctx, "SAVEPOINT "+onErrorRollbackSavepoint); err != nil {
+		return nil, err
+	}
+
+	res, err := e.query(ctx, sql, args...)
+	var commandTag string
+	if err == nil {
+		if queryResult, ok := res.(*result.QueryResult); ok {
+			err = queryResult.Materialize()
+			commandTag = queryResult.CommandTag()
+		}
+	}
+
+	// Mirrors psql: roll back to the savepoint if the statement aborted the
+	// transaction, and leave it alone if the statement ended the transaction
+	// or managed savepoints itself.
+	var cleanup string
+	switch e.transactionStatus() {
+	case TxFailed:
+		cleanup = "ROLLBACK TO SAVEPOINT " + onErrorRollbackSavepoint
+	case TxActive:
+		if !touchesSavepoints(commandTag) {
+			cleanup = "RELEASE SAVEPOINT " + onErrorRollbackSavepoint
+		}
+	}
+	if cleanup != "" {
+		if _, cleanupErr := e.Conn.Exec(ctx, cleanup); cleanupErr != nil {
+			e.Logger.Error("Savepoint cleanup failed", "error", cleanupErr, "command", cleanup)
+			err = errors.Join(err, fmt.Errorf("on_error_rollback: %w", cleanupErr))
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (e *executor) transactionStatus() TxStatus {
+	if e.txStatus != nil {
+		return e.txStatus()
+	}
+	return e.status().TxStatus
+}
+
+func touchesSavepoints(commandTag string) bool {
+	switch commandTag {
+	case "COMMIT", "SAVEPOINT", "RELEASE", "ROLLBACK":
+		return true
+	default:
+		return false
+	}
+}
+
+// begin opens a transaction block for autocommit-off sessions.
+func (e *executor) begin(ctx context.Context) error {
+	e.Logger.Debug("Issuing implicit BEGIN")
+	_, err := e.Conn.Exec(ctx, "BEGIN")
+	return err
+}
+
 func (e *executor) executeSpecial(ctx context.Context, cmd string) (pgxspecial.SpecialCommandResult, bool, error) {
 	specialResult, ok, err := pgxspecial.ExecuteSpecialCommand(ctx, e.Conn, cmd)
 	if err != nil {
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"errors"
 	"io"
 	"log/slog"
 	"testing"
@@ -171,6 +172,139 @@ func TestExecutorExecute(t *testing.T) {
 	}
 }
 
+func TestExecutorExecuteWithSavepoint(t *testing.T) {
+	ctx := context.Background()
+	const savepoint = "SAVEPOINT pgxcli_on_error_rollback"
+
+	testCases := []struct {
+		name         string
+		query        string
+		rows         *MockRows
+		savepointErr error
+		queryErr     error
+		wantRows     [][]any
+		wantErr      error
+	}{
+		{
+			name:  "materializes rows after savepoint",
+			query: "select id from users",
+			rows: &MockRows{
+				fields: []pgconn.FieldDescription{{Name: "id"}},
+				data:   [][]any{{1}, {2}},
+				tag:    pgconn.NewCommandTag("SELECT 2"),
+			},
+			wantRows: [][]any{{1}, {2}},
+		},
+		{
+			name:     "returns statement error",
+			query:    "select * from missing",
+			rows:     &MockRows{},
+			queryErr: assert.AnError,
+			wantErr:  assert.AnError,
+		},
+		{
+			name:         "returns savepoint error without running statement",
+			query:        "select 1",
+			savepointErr: assert.AnError,
+			wantErr:      assert.AnError,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			conn := new(MockConn)
+			conn.On("Exec", ctx, savepoint).Return(pgconn.CommandTag{}, tc.savepointErr)
+			if tc.savepointErr == nil {
+				conn.On("Query", ctx, tc.query).Return(tc.rows, tc.queryErr)
+			}
+
+			exec := &executor{Conn: conn, Logger: slog.Default()}
+			result, err := exec.executeWithSavepoint(ctx, tc.query)
+			conn.AssertExpectations(t)
+
+			if tc.wantErr != nil {
+				assert.Nil(t, result)
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			queryResult, ok := result.(*dbresult.QueryResult)
+			require.True(t, ok)
+			rows, err := queryResult.Rows()
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantRows, rows)
+			assert.Equal(t, []string{"id"}, queryResult.Columns())
+		})
+	}
+}
+
+func TestExecutorExecuteWithSavepoint_Cleanup(t *testing.T) {
+	ctx := context.Background()
+	const (
+		savepoint  = "SAVEPOINT pgxcli_on_error_rollback"
+		rollbackTo = "ROLLBACK TO SAVEPOINT pgxcli_on_error_rollback"
+		release    = "RELEASE SAVEPOINT pgxcli_on_error_rollback"
+	)
+
+	testCases := []struct {
+		name        string
+		tag         string
+		rowsErr     error
+		status      TxStatus
+		cleanupErr  error
+		wantCleanup string
+		wantErr     error
+	}{
+		{name: "rolls back to savepoint on failed statement", tag: "", rowsErr: assert.AnError, status: TxFailed, wantCleanup: rollbackTo, wantErr: assert.AnError},
+		{name: "releases savepoint on success", tag: "UPDATE 1", status: TxActive, wantCleanup: release},
+		{name: "leaves COMMIT alone", tag: "COMMIT", status: TxIdle},
+		{name: "leaves ROLLBACK alone", tag: "ROLLBACK", status: TxIdle},
+		{name: "leaves user SAVEPOINT alone", tag: "SAVEPOINT", status: TxActive},
+		{name: "leaves RELEASE alone", tag: "RELEASE", status: TxActive},
+		{name: "reports cleanup failure", tag: "UPDATE 1", status: TxActive, cleanupErr: errors.New("cleanup failed"), wantCleanup: release, wantErr: errors.New("on_error_rollback: cleanup failed")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			const query = "update users set name = 'x'"
+			conn := new(MockConn)
+			conn.On("Exec", ctx, savepoint).Return(pgconn.CommandTag{}, nil)
+			conn.On("Query", ctx, query).Return(&MockRows{tag: pgconn.NewCommandTag(tc.tag), err: tc.rowsErr}, nil)
+			if tc.wantCleanup != "" {
+				conn.On("Exec", ctx, tc.wantCleanup).Return(pgconn.CommandTag{}, tc.cleanupErr)
+			}
+
+			exec := &executor{Conn: conn, Logger: slog.Default(), txStatus: func() TxStatus { return tc.status }}
+			result, err := exec.executeWithSavepoint(ctx, query)
+			conn.AssertExpectations(t)
+			wantExecs := 1
+			if tc.wantCleanup != "" {
+				wantExecs++
+			}
+			conn.AssertNumberOfCalls(t, "Exec", wantExecs)
+
+			if tc.wantErr != nil {
+				assert.Nil(t, result)
+				assert.ErrorContains(t, err, tc.wantErr.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.tag, result.(*dbresult.QueryResult).CommandTag())
+		})
+	}
+}
+
+func TestTouchesSavepoints(t *testing.T) {
+	for _, tag := range []string{"COMMIT", "SAVEPOINT", "RELEASE", "ROLLBACK"} {
+		assert.True(t, touchesSavepoints(tag), tag)
+	}
+	for _, tag := range []string{"SELECT 1", "INSERT 0 1", "UPDATE 3", ""} {
+		assert.False(t, touchesSavepoints(tag), tag)
+	}
+}
+
 func TestExecutorPing(t *testing.T) {
 	ctx := context.Background()
 	testCases := []struct {
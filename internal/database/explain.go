@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/balaji01-4d/pgxspecial/database"
+)
+
+// ExplainAction carries the JSON plan captured by \explain, or asks to
+// compare the last two captured plans.
+type ExplainAction struct {
+	Query   string
+	Analyze bool
+	// Diff is set for \explain diff, which runs no query.
+	Diff bool
+	// Plan is the raw EXPLAIN (FORMAT JSON) output.
+	Plan []byte
+}
+
+// ResultKind returns the special result kind for ExplainAction.
+func (a ExplainAction) ResultKind() pgxspecial.SpecialResultKind {
+	return Explain
+}
+
+// parseExplainArgs splits \explain arguments into the optional ANALYZE
+// keyword and the query, or recognizes \explain diff.
+func parseExplainArgs(args string) (ExplainAction, error) {
+	query := strings.TrimSpace(args)
+	if strings.EqualFold(strings.TrimRight(query, "; \t\n"), "diff") {
+		return ExplainAction{Diff: true}, nil
+	}
+	first, rest, _ := strings.Cut(query, " ")
+	action := ExplainAction{}
+	if strings.EqualFold(first, "analyze") {
+		action.Analyze = true
+		query = strings.TrimSpace(rest)
+	}
+
+	action.Query = strings.TrimSpace(strings.TrimRight(query, "; \t\n"))
+	if action.Query == "" {
+		return ExplainAction{}, errors.New("\\explain: query is required")
+	}
+	return action, nil
+}
+
+// statement returns the EXPLAIN statement for the action. ANALYZE runs the
+// query, so it also collects buffer usage.
+func (a ExplainAction) statement() string {
+	if a.Analyze {
+		return "EXPLAIN (FORMAT JSON, ANALYZE, BUFFERS) " + a.Query
+	}
+	return "EXPLAIN (FORMAT JSON) " + a.Query
+}
+
+func explain(ctx context.Context, db database.Queryer, args string) (pgxspecial.SpecialCommandResult, error) {
+	action, err := parseExplainArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	if action.Diff {
+		return action, nil
+	}
+
+	var plan string
+	if err := db.QueryRow(ctx, action.statement()).Scan(&plan); err != nil {
+		return nil, err
+	}
+	action.Plan = []byte(plan)
+	return action, nil
+}
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExplainArgs(t *testing.T) {
+	testCases := []struct {
+		name     string
+		args     string
+		want     ExplainAction
+		wantStmt string
+		wantErr  string
+	}{
+		{
+			name:     "estimates only",
+			args:     "select * from users;",
+			want:     ExplainAction{Query: "select * from users"},
+			wantStmt: "EXPLAIN (FORMAT JSON) select * from users",
+		},
+		{
+			name:     "analyze",
+			args:     "ANALYZE select 1",
+			want:     ExplainAction{Query: "select 1", Analyze: true},
+			wantStmt: "EXPLAIN (FORMAT JSON, ANALYZE, BUFFERS) select 1",
+		},
+		{
+			name: "analyze prefix of a word is part of the query",
+			args: "analyzed_view",
+			want: ExplainAction{Query: "analyzed_view"},
+		},
+		{name: "diff", args: "DIFF;", want: ExplainAction{Diff: true}},
+		{
+			name:     "diff as a query word",
+			args:     "analyze diff",
+			want:     ExplainAction{Query: "diff", Analyze: true},
+			wantStmt: "EXPLAIN (FORMAT JSON, ANALYZE, BUFFERS) diff",
+		},
+		{name: "empty", args: "  ", wantErr: "\\explain: query is required"},
+		{name: "analyze without query", args: "analyze ;", wantErr: "\\explain: query is required"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseExplainArgs(tc.args)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+			if tc.wantStmt != "" {
+				assert.Equal(t, tc.wantStmt, got.statement())
+			}
+		})
+	}
+}
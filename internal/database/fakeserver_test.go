@@ -0,0 +1,104 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServer speaks just enough of the PostgreSQL protocol to test how the
+// client uses a connection. Each frontend message after startup is passed
+// to respond, and the messages it returns are sent back.
+type fakeServer struct {
+	ln      net.Listener
+	respond func(pgproto3.FrontendMessage) []pgproto3.BackendMessage
+	// cancels receives a value for each cancel request.
+	cancels chan struct{}
+}
+
+func startFakeServer(t *testing.T, respond func(pgproto3.FrontendMessage) []pgproto3.BackendMessage) *fakeServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	s := &fakeServer{ln: ln, respond: respond, cancels: make(chan struct{}, 8)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeServer) connString() string {
+	return fmt.Sprintf("postgres://app@%s/app?sslmode=disable", s.ln.Addr())
+}
+
+// connect opens a client on the server through a pgxcli connector.
+func (s *fakeServer) connect(t *testing.T) *Client {
+	t.Helper()
+	connector, err := NewPGConnectorFromConnString(s.connString())
+	require.NoError(t, err)
+
+	client := &Client{logger: slog.New(slog.NewTextHandler(io.Discard, nil)), autocommit: true}
+	require.NoError(t, client.Connect(t.Context(), connector))
+	t.Cleanup(func() { client.executor.Conn.Close(t.Context()) })
+	return client
+}
+
+func (s *fakeServer) serve(conn net.Conn) {
+	defer conn.Close()
+	backend := pgproto3.NewBackend(conn, conn)
+
+	startup, err := backend.ReceiveStartupMessage()
+	if err != nil {
+		return
+	}
+	if _, ok := startup.(*pgproto3.CancelRequest); ok {
+		s.cancels <- struct{}{}
+		return
+	}
+
+	backend.Send(&pgproto3.AuthenticationOk{})
+	backend.Send(&pgproto3.ParameterStatus{Name: "server_version", Value: "17.0"})
+	backend.Send(&pgproto3.BackendKeyData{ProcessID: 42, SecretKey: []byte{0, 0, 0, 1}})
+	backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+	if err := backend.Flush(); err != nil {
+		return
+	}
+
+	for {
+		msg, err := backend.Receive()
+		if err != nil {
+			return
+		}
+		if _, ok := msg.(*pgproto3.Terminate); ok {
+			return
+		}
+		for _, reply := range s.respond(msg) {
+			backend.Send(reply)
+		}
+		if err := backend.Flush(); err != nil && !errors.Is(err, net.ErrClosed) {
+			return
+		}
+	}
+}
+
+// pingReplies answers the empty query pgx pings with.
+func pingReplies(msg pgproto3.FrontendMessage) []pgproto3.BackendMessage {
+	if _, ok := msg.(*pgproto3.Query); ok {
+		return []pgproto3.BackendMessage{&pgproto3.EmptyQueryResponse{}, &pgproto3.ReadyForQuery{TxStatus: 'I'}}
+	}
+	return nil
+}
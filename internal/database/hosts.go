@@ -0,0 +1,91 @@
+package database
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// connectedHost returns which of the configured hosts a connection with the
+// remote address remote was made to. pgx tries the hosts of a multi-host
+// connection string in turn, and keeps only the first one in its config, so
+// the host is found by matching remote against each of them. When none
+// matches, the remote address itself is returned. Connections through an
+// SSH tunnel have no remote address and report the first host.
+func connectedHost(ctx context.Context, cfg *pgconn.Config, remote net.Addr) (string, uint16) {
+	if len(cfg.Fallbacks) == 0 || (remoteIP(remote) == "" && !isUnix(remote)) {
+		return cfg.Host, cfg.Port
+	}
+
+	hosts := append([]*pgconn.FallbackConfig{{Host: cfg.Host, Port: cfg.Port}}, cfg.Fallbacks...)
+	for _, h := range hosts {
+		if hostMatches(ctx, cfg, h, remote) {
+			return h.Host, h.Port
+		}
+	}
+
+	if addr, ok := remote.(*net.TCPAddr); ok {
+		return addr.IP.String(), uint16(addr.Port)
+	}
+	return remote.String(), 0
+}
+
+// hostMatches reports whether h, once resolved, is the address remote.
+func hostMatches(ctx context.Context, cfg *pgconn.Config, h *pgconn.FallbackConfig, remote net.Addr) bool {
+	network, address := pgconn.NetworkAddress(h.Host, h.Port)
+	if network == "unix" {
+		return isUnix(remote) && remote.String() == address
+	}
+
+	addr, ok := remote.(*net.TCPAddr)
+	if !ok || int(h.Port) != addr.Port {
+		return false
+	}
+	if ip := net.ParseIP(h.Host); ip != nil {
+		return ip.Equal(addr.IP)
+	}
+	lookup := cfg.LookupFunc
+	if lookup == nil {
+		lookup = net.DefaultResolver.LookupHost
+	}
+	ips, err := lookup(ctx, h.Host)
+	if err != nil {
+		return false
+	}
+	for _, resolved := range ips {
+		if ip := net.ParseIP(resolved); ip != nil && ip.Equal(addr.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteIP returns the IP address of remote, or an empty string for a unix
+// socket or an unknown address.
+func remoteIP(remote net.Addr) string {
+	if addr, ok := remote.(*net.TCPAddr); ok && addr.IP != nil && !addr.IP.IsUnspecified() {
+		return addr.IP.String()
+	}
+	return ""
+}
+
+func isUnix(remote net.Addr) bool {
+	return remote != nil && remote.Network() == "unix"
+}
+
+// AddConnSetting returns connString, a URI or keyword/value connection
+// string, with the setting key set to value.
+func AddConnSetting(connString, key, value string) string {
+	if !strings.HasPrefix(connString, "postgres://") && !strings.HasPrefix(connString, "postgresql://") {
+		return connString + " " + connSetting(key, value)
+	}
+	// A multi-host URI is not a valid net/url URL, so the query is added by hand.
+	sep := "?"
+	if strings.Contains(connString, "?") {
+		sep = "&"
+	}
+	return connString + sep + url.QueryEscape(key) + "=" + url.QueryEscape(value)
+}
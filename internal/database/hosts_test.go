@@ -0,0 +1,78 @@
+package database
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnectedHost(t *testing.T) {
+	cfg := &pgconn.Config{
+		Host: "primary.internal",
+		Port: 5432,
+		Fallbacks: []*pgconn.FallbackConfig{
+			{Host: "standby.internal", Port: 5432},
+			{Host: "10.0.0.9", Port: 6432},
+			{Host: "/var/run/postgresql", Port: 5432},
+		},
+		LookupFunc: func(_ context.Context, host string) ([]string, error) {
+			switch host {
+			case "primary.internal":
+				return []string{"10.0.0.1"}, nil
+			case "standby.internal":
+				return []string{"10.0.0.2", "fd00::2"}, nil
+			}
+			return nil, &net.DNSError{Err: "no such host", Name: host}
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		cfg      *pgconn.Config
+		remote   net.Addr
+		wantHost string
+		wantPort uint16
+	}{
+		{name: "first host", cfg: cfg, remote: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 5432}, wantHost: "primary.internal", wantPort: 5432},
+		{name: "second host", cfg: cfg, remote: &net.TCPAddr{IP: net.ParseIP("fd00::2"), Port: 5432}, wantHost: "standby.internal", wantPort: 5432},
+		{name: "ip host", cfg: cfg, remote: &net.TCPAddr{IP: net.ParseIP("10.0.0.9"), Port: 6432}, wantHost: "10.0.0.9", wantPort: 6432},
+		{name: "unix socket", cfg: cfg, remote: &net.UnixAddr{Name: "/var/run/postgresql/.s.PGSQL.5432", Net: "unix"}, wantHost: "/var/run/postgresql", wantPort: 5432},
+		{name: "no match", cfg: cfg, remote: &net.TCPAddr{IP: net.ParseIP("10.0.0.7"), Port: 5432}, wantHost: "10.0.0.7", wantPort: 5432},
+		{name: "through a tunnel", cfg: cfg, remote: &net.TCPAddr{IP: net.IPv4zero}, wantHost: "primary.internal", wantPort: 5432},
+		{name: "single host", cfg: &pgconn.Config{Host: "db", Port: 5433}, remote: &net.TCPAddr{IP: net.ParseIP("10.0.0.7"), Port: 5433}, wantHost: "db", wantPort: 5433},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			host, port := connectedHost(context.Background(), tc.cfg, tc.remote)
+			assert.Equal(t, tc.wantHost, host)
+			assert.Equal(t, tc.wantPort, port)
+		})
+	}
+}
+
+func TestAddConnSetting(t *testing.T) {
+	testCases := []struct {
+		name       string
+		connString string
+		want       string
+	}{
+		{name: "uri", connString: "postgres://a:5432,b:5432/app", want: "postgres://a:5432,b:5432/app?target_session_attrs=read-write"},
+		{name: "uri with query", connString: "postgresql://a/app?sslmode=require", want: "postgresql://a/app?sslmode=require&target_session_attrs=read-write"},
+		{name: "keyword/value", connString: "host=a,b dbname=app", want: "host=a,b dbname=app target_session_attrs='read-write'"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := AddConnSetting(tc.connString, "target_session_attrs", "read-write")
+			assert.Equal(t, tc.want, got)
+
+			cfg, err := pgconn.ParseConfig(got)
+			assert.NoError(t, err)
+			assert.NotNil(t, cfg.ValidateConnect)
+		})
+	}
+}
@@ -0,0 +1,275 @@
+package database
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/balaji01-4d/pgxcli/internal/csvinfer"
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// importSampleRows is how many records are sampled to infer column types.
+	importSampleRows = 1000
+	// maxRejectedSamples limits how many rejected rows are kept for reporting.
+	maxRejectedSamples = 10
+)
+
+// ImportAction carries the file and optional target table for \import.
+type ImportAction struct {
+	Path  string
+	Table string
+}
+
+// ResultKind returns the special result kind for ImportAction.
+func (a ImportAction) ResultKind() pgxspecial.SpecialResultKind {
+	return Import
+}
+
+func parseImportArgs(args string) (ImportAction, error) {
+	tokens, err := copyTokens(args)
+	if err != nil {
+		return ImportAction{}, errors.New("\\import: " + strings.TrimPrefix(err.Error(), "\\copy: "))
+	}
+	if len(tokens) == 0 || len(tokens) > 2 {
+		return ImportAction{}, errors.New("\\import: expected a file name and an optional table name")
+	}
+
+	action := ImportAction{Path: tokens[0]}
+	if strings.HasPrefix(action.Path, "'") {
+		action.Path = strings.ReplaceAll(action.Path[1:len(action.Path)-1], "''", "'")
+	}
+	if len(tokens) == 2 {
+		action.Table = tokens[1]
+	}
+	return action, nil
+}
+
+// ImportPlan is the proposed table for a CSV import, inferred from a sample
+// of the file.
+type ImportPlan struct {
+	Path    string
+	Table   string
+	Columns []csvinfer.Column
+	// Sampled is the number of records the types were inferred from.
+	Sampled int
+}
+
+// CreateTable returns the statement that creates the target table.
+func (p ImportPlan) CreateTable() string {
+	return csvinfer.CreateTable(p.Table, p.Columns)
+}
+
+// RejectedRow is a CSV record that was skipped during an import.
+type RejectedRow struct {
+	Line int
+	Err  error
+}
+
+// ImportResult summarizes a completed import.
+type ImportResult struct {
+	Rows     int64
+	Rejected int
+	// Samples holds the first rejected rows, up to maxRejectedSamples.
+	Samples []RejectedRow
+}
+
+// PlanImport samples the CSV file in action and infers the target table.
+// Without an explicit table name, one is derived from the file name.
+func PlanImport(action ImportAction) (ImportPlan, error) {
+	f, r, err := openCSV(action.Path)
+	if err != nil {
+		return ImportPlan{}, err
+	}
+	defer f.Close()
+
+	header, rows, err := csvinfer.Sample(csv.NewReader(r), importSampleRows)
+	if err != nil {
+		return ImportPlan{}, fmt.Errorf("\\import: reading %s: %w", action.Path, err)
+	}
+
+	table := action.Table
+	if table == "" {
+		base := filepath.Base(action.Path)
+		for ext := filepath.Ext(base); ext != ""; ext = filepath.Ext(base) {
+			base = strings.TrimSuffix(base, ext)
+		}
+		table = csvinfer.Identifier(base)
+		if table == "" {
+			return ImportPlan{}, fmt.Errorf("\\import: cannot derive a table name from %q", action.Path)
+		}
+	}
+
+	return ImportPlan{
+		Path:    action.Path,
+		Table:   table,
+		Columns: csvinfer.Infer(header, rows),
+		Sampled: len(rows),
+	}, nil
+}
+
+// openCSV opens path, transparently decompressing gzip files. The returned
+// closer must be closed by the caller.
+func openCSV(path string) (io.Closer, io.Reader, error) {
+	f, err := os.Open(expandHome(path))
+	if err != nil {
+		return nil, nil, fmt.Errorf("\\import: %w", err)
+	}
+	if !isGzipPath(path) {
+		return f, f, nil
+	}
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("\\import: %w", err)
+	}
+	return f, zr, nil
+}
+
+// Import creates the planned table and loads the file with COPY. Records
+// that do not match the inferred columns are skipped and reported instead
+// of aborting the load. Outside a transaction block, the table creation and
+// load are committed together.
+func (c *Client) Import(ctx context.Context, plan ImportPlan, progress func(CopyProgress)) (ImportResult, error) {
+	if c.executor == nil {
+		return ImportResult{}, errors.New("not connected to any database")
+	}
+	pgConn := c.executor.Conn.PgConn()
+	if pgConn == nil {
+		return ImportResult{}, errors.New("\\import: connection does not support the COPY protocol")
+	}
+
+	ownTx := c.Status().TxStatus == TxIdle
+	if ownTx {
+		if err := c.executor.begin(ctx); err != nil {
+			return ImportResult{}, c.RecoverConnection(ctx, err)
+		}
+	}
+
+	res, err := c.importRows(ctx, plan, progress)
+	if err != nil {
+		if ownTx {
+			if _, rbErr := c.executor.Conn.Exec(ctx, "ROLLBACK"); rbErr != nil {
+				err = errors.Join(err, rbErr)
+			}
+		}
+		return res, c.RecoverConnection(ctx, err)
+	}
+
+	if ownTx {
+		if _, err := c.executor.Conn.Exec(ctx, "COMMIT"); err != nil {
+			return res, c.RecoverConnection(ctx, err)
+		}
+	}
+	c.logger.Info("Import finished", "table", plan.Table, "rows", res.Rows, "rejected", res.Rejected)
+	return res, nil
+}
+
+func (c *Client) importRows(ctx context.Context, plan ImportPlan, progress func(CopyProgress)) (ImportResult, error) {
+	if _, err := c.executor.Conn.Exec(ctx, plan.CreateTable()); err != nil {
+		return ImportResult{}, err
+	}
+
+	f, r, err := openCSV(plan.Path)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	defer f.Close()
+
+	names := make([]string, len(plan.Columns))
+	for i, col := range plan.Columns {
+		names[i] = pgx.Identifier{col.Name}.Sanitize()
+	}
+	stmt := fmt.Sprintf("COPY %s (%s) FROM STDIN WITH (FORMAT csv)",
+		pgx.Identifier(strings.Split(plan.Table, ".")).Sanitize(), strings.Join(names, ", "))
+
+	pr, pw := io.Pipe()
+	counter := &copyCounter{progress: progress}
+	var res ImportResult
+	done := make(chan error, 1)
+	go func() {
+		err := filterRecords(plan.Columns, r, &countingWriter{w: pw, counter: counter}, &res)
+		pw.CloseWithError(err)
+		done <- err
+	}()
+
+	tag, err := c.executor.Conn.PgConn().CopyFrom(ctx, pr, stmt)
+	pr.CloseWithError(errors.New("copy finished"))
+	if filterErr := <-done; err == nil && filterErr != nil {
+		err = filterErr
+	}
+	if err != nil {
+		return res, err
+	}
+	res.Rows = tag.RowsAffected()
+	return res, nil
+}
+
+// filterRecords copies valid CSV records from r to w, recording rejected
+// ones in res. The header record is skipped.
+func filterRecords(cols []csvinfer.Column, r io.Reader, w io.Writer, res *ImportResult) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	if _, err := reader.Read(); err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var parseErr *csv.ParseError
+		switch {
+		case errors.As(err, &parseErr):
+			res.reject(parseErr.StartLine, parseErr.Err)
+			continue
+		case err != nil:
+			return err
+		}
+
+		// FieldPos is only valid after a successful Read.
+		line, _ := reader.FieldPos(0)
+		if err := csvinfer.Validate(cols, record); err != nil {
+			res.reject(line, err)
+			continue
+		}
+		if err := writer.Write(blanksToNull(cols, record)); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// blanksToNull empties the blank cells of non-text columns, which pass
+// validation as NULLs. COPY reads an empty unquoted field as NULL, while
+// csv.Writer quotes a cell starting with a space, which COPY reads as a
+// string the column type rejects.
+func blanksToNull(cols []csvinfer.Column, record []string) []string {
+	for i, col := range cols {
+		if col.Type != csvinfer.Text && strings.TrimSpace(record[i]) == "" {
+			record[i] = ""
+		}
+	}
+	return record
+}
+
+func (r *ImportResult) reject(line int, err error) {
+	r.Rejected++
+	if len(r.Samples) < maxRejectedSamples {
+		r.Samples = append(r.Samples, RejectedRow{Line: line, Err: err})
+	}
+}
@@ -0,0 +1,137 @@
+package database
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/balaji01-4d/pgxcli/internal/csvinfer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseImportArgs(t *testing.T) {
+	testCases := []struct {
+		name    string
+		args    string
+		want    ImportAction
+		wantErr string
+	}{
+		{name: "file only", args: "data.csv", want: ImportAction{Path: "data.csv"}},
+		{name: "file and table", args: "data.csv sales.orders", want: ImportAction{Path: "data.csv", Table: "sales.orders"}},
+		{name: "quoted file", args: "'my data.csv'", want: ImportAction{Path: "my data.csv"}},
+		{name: "missing file", args: "", wantErr: "expected a file name"},
+		{name: "too many arguments", args: "a b c", wantErr: "expected a file name"},
+		{name: "unterminated quote", args: "'a.csv", wantErr: "\\import: unterminated quoted string"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseImportArgs(tc.args)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestPlanImport(t *testing.T) {
+	dir := t.TempDir()
+	data := "Order ID,Amount,Shipped\n1,9.50,true\n2,12,false\n"
+
+	plain := filepath.Join(dir, "Monthly Orders.csv")
+	require.NoError(t, os.WriteFile(plain, []byte(data), 0o600))
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	_, err := zw.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	compressed := filepath.Join(dir, "orders.csv.gz")
+	require.NoError(t, os.WriteFile(compressed, gz.Bytes(), 0o600))
+
+	wantCols := []csvinfer.Column{
+		{Name: "order_id", Type: csvinfer.Integer},
+		{Name: "amount", Type: csvinfer.Numeric},
+		{Name: "shipped", Type: csvinfer.Boolean},
+	}
+
+	plan, err := PlanImport(ImportAction{Path: plain})
+	require.NoError(t, err)
+	assert.Equal(t, "monthly_orders", plan.Table)
+	assert.Equal(t, wantCols, plan.Columns)
+	assert.Equal(t, 2, plan.Sampled)
+
+	plan, err = PlanImport(ImportAction{Path: compressed, Table: "archive.orders"})
+	require.NoError(t, err)
+	assert.Equal(t, "archive.orders", plan.Table)
+	assert.Equal(t, wantCols, plan.Columns)
+	assert.Contains(t, plan.CreateTable(), `CREATE TABLE "archive"."orders"`)
+
+	_, err = PlanImport(ImportAction{Path: filepath.Join(dir, "missing.csv")})
+	assert.Error(t, err)
+}
+
+func TestFilterRecords(t *testing.T) {
+	cols := []csvinfer.Column{
+		{Name: "id", Type: csvinfer.Integer},
+		{Name: "name", Type: csvinfer.Text},
+	}
+	input := "id,name\n1,alice\nx,bob\n3\n4,\"dave\"\n5,\"broken\"x\n6,frank\n"
+
+	var out bytes.Buffer
+	var res ImportResult
+	require.NoError(t, filterRecords(cols, strings.NewReader(input), &out, &res))
+
+	assert.Equal(t, "1,alice\n4,dave\n6,frank\n", out.String())
+	assert.Equal(t, 3, res.Rejected)
+	require.Len(t, res.Samples, 3)
+	assert.Equal(t, 3, res.Samples[0].Line)
+	assert.Contains(t, res.Samples[0].Err.Error(), `"x" is not a valid bigint`)
+	assert.Equal(t, 4, res.Samples[1].Line)
+	assert.Contains(t, res.Samples[1].Err.Error(), "expected 2 fields, got 1")
+	assert.Equal(t, 6, res.Samples[2].Line)
+}
+
+func TestFilterRecords_MalformedFirstField(t *testing.T) {
+	cols := []csvinfer.Column{
+		{Name: "name", Type: csvinfer.Text},
+		{Name: "friend", Type: csvinfer.Text},
+	}
+	input := "name,friend\n\"bad\"x,bob\nalice,carol\n"
+
+	var out bytes.Buffer
+	var res ImportResult
+	require.NoError(t, filterRecords(cols, strings.NewReader(input), &out, &res))
+
+	assert.Equal(t, "alice,carol\n", out.String())
+	assert.Equal(t, 1, res.Rejected)
+	require.Len(t, res.Samples, 1)
+	assert.Equal(t, 2, res.Samples[0].Line)
+	assert.ErrorIs(t, res.Samples[0].Err, csv.ErrQuote)
+}
+
+func TestFilterRecords_BlankCellsAreNull(t *testing.T) {
+	cols := []csvinfer.Column{
+		{Name: "id", Type: csvinfer.Integer},
+		{Name: "score", Type: csvinfer.Numeric},
+		{Name: "name", Type: csvinfer.Text},
+	}
+	input := "id,score,name\n1, ,alice\n\" \",2.5,  \n"
+
+	var out bytes.Buffer
+	var res ImportResult
+	require.NoError(t, filterRecords(cols, strings.NewReader(input), &out, &res))
+
+	assert.Equal(t, "1,,alice\n,2.5,\"  \"\n", out.String(), "blank numbers load as NULL, text keeps its spaces")
+	assert.Zero(t, res.Rejected)
+}
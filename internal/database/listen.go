@@ -0,0 +1,182 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Notification is an asynchronous NOTIFY message received on a channel the
+// session listens on.
+type Notification struct {
+	Channel string
+	Payload string
+	PID     uint32
+}
+
+func (n Notification) String() string {
+	if n.Payload == "" {
+		return fmt.Sprintf("Asynchronous notification %q received from server process with PID %d.", n.Channel, n.PID)
+	}
+	return fmt.Sprintf("Asynchronous notification %q with payload %q received from server process with PID %d.",
+		n.Channel, n.Payload, n.PID)
+}
+
+// ListenAction carries the channel for \listen and \unlisten. An empty
+// Channel with Listen false unlistens from every channel.
+type ListenAction struct {
+	Channel string
+	Listen  bool
+}
+
+// ResultKind returns the special result kind for ListenAction.
+func (a ListenAction) ResultKind() pgxspecial.SpecialResultKind {
+	return Listen
+}
+
+func parseListenArgs(args string, listen bool) (ListenAction, error) {
+	cmd := "\\unlisten"
+	if listen {
+		cmd = "\\listen"
+	}
+
+	channel := strings.TrimSpace(args)
+	switch {
+	case strings.HasPrefix(channel, `"`) && strings.HasSuffix(channel, `"`) && len(channel) > 1:
+		channel = strings.ReplaceAll(channel[1:len(channel)-1], `""`, `"`)
+	case strings.ContainsAny(channel, " \t"):
+		return ListenAction{}, fmt.Errorf("%s: expected a single channel name", cmd)
+	case channel == "*" && !listen:
+		channel = ""
+	default:
+		channel = strings.ToLower(channel)
+	}
+
+	if channel == "" && listen {
+		return ListenAction{}, fmt.Errorf("%s: channel name is required", cmd)
+	}
+	return ListenAction{Channel: channel, Listen: listen}, nil
+}
+
+// listener is the background wait for notifications while the session is idle.
+type listener struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Listen subscribes the session to channel.
+func (c *Client) Listen(ctx context.Context, channel string) error {
+	if c.executor == nil {
+		return errors.New("not connected to any database")
+	}
+	if _, err := c.executor.Conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return c.RecoverConnection(ctx, err)
+	}
+	if !slices.Contains(c.channels, channel) {
+		c.channels = append(c.channels, channel)
+	}
+	return nil
+}
+
+// Unlisten unsubscribes the session from channel, or from every channel
+// when channel is empty.
+func (c *Client) Unlisten(ctx context.Context, channel string) error {
+	if c.executor == nil {
+		return errors.New("not connected to any database")
+	}
+
+	stmt := "UNLISTEN *"
+	if channel != "" {
+		stmt = "UNLISTEN " + pgx.Identifier{channel}.Sanitize()
+	}
+	if _, err := c.executor.Conn.Exec(ctx, stmt); err != nil {
+		return c.RecoverConnection(ctx, err)
+	}
+
+	if channel == "" {
+		c.channels = nil
+	} else {
+		c.channels = slices.DeleteFunc(c.channels, func(ch string) bool { return ch == channel })
+	}
+	return nil
+}
+
+// Channels returns the channels the session listens on.
+func (c *Client) Channels() []string {
+	return slices.Clone(c.channels)
+}
+
+// queueNotification is the notification handler of the client's
+// connections. It runs on whichever goroutine is reading from the
+// connection, so a notification received while a statement runs waits here
+// until the result is done.
+func (c *Client) queueNotification(_ *pgconn.PgConn, n *pgconn.Notification) {
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+	c.pending = append(c.pending, Notification{Channel: n.Channel, Payload: n.Payload, PID: n.PID})
+}
+
+// takeNotifications returns the queued notifications and empties the queue.
+func (c *Client) takeNotifications() []Notification {
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+	pending := c.pending
+	c.pending = nil
+	return pending
+}
+
+// StartListening passes the notifications received since it last ran to
+// onNotification, then waits for more in the background, passing each one
+// on from the background goroutine. It only waits when the session listens
+// on at least one channel. The connection must not be used until
+// StopListening returns.
+func (c *Client) StartListening(onNotification func(Notification)) {
+	for _, n := range c.takeNotifications() {
+		onNotification(n)
+	}
+	if c.listener != nil || len(c.channels) == 0 || c.executor == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	l := &listener{cancel: cancel, done: make(chan struct{})}
+	conn := c.executor.Conn
+	go func() {
+		defer close(l.done)
+		for {
+			// the notification reaches queueNotification; n is only set
+			// when pgx buffered it instead
+			n, err := conn.WaitForNotification(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					c.logger.Warn("Stopped waiting for notifications", "error", err)
+				}
+				return
+			}
+			if n != nil {
+				c.queueNotification(nil, n)
+			}
+			for _, n := range c.takeNotifications() {
+				onNotification(n)
+			}
+		}
+	}()
+	c.listener = l
+}
+
+// StopListening stops the background wait started by StartListening and
+// returns once the connection is free to use again.
+func (c *Client) StopListening() {
+	if c.listener == nil {
+		return
+	}
+	c.listener.cancel()
+	<-c.listener.done
+	c.listener = nil
+}
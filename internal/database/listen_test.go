@@ -0,0 +1,165 @@
+package database
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/balaji01-4d/pgxcli/internal/database/result"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseListenArgs(t *testing.T) {
+	testCases := []struct {
+		name    string
+		args    string
+		listen  bool
+		want    ListenAction
+		wantErr string
+	}{
+		{name: "folds unquoted name", args: " Jobs ", listen: true, want: ListenAction{Channel: "jobs", Listen: true}},
+		{name: "keeps quoted name", args: `"Jobs ""v2"""`, listen: true, want: ListenAction{Channel: `Jobs "v2"`, Listen: true}},
+		{name: "unlisten channel", args: "jobs", want: ListenAction{Channel: "jobs"}},
+		{name: "unlisten all", args: "*", want: ListenAction{}},
+		{name: "unlisten without argument", args: "", want: ListenAction{}},
+		{name: "listen requires channel", args: "", listen: true, wantErr: "\\listen: channel name is required"},
+		{name: "rejects extra words", args: "a b", listen: true, wantErr: "\\listen: expected a single channel name"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseListenArgs(tc.args, tc.listen)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestNotificationString(t *testing.T) {
+	n := Notification{Channel: "jobs", Payload: "42", PID: 1234}
+	assert.Equal(t, `Asynchronous notification "jobs" with payload "42" received from server process with PID 1234.`, n.String())
+
+	n.Payload = ""
+	assert.Equal(t, `Asynchronous notification "jobs" received from server process with PID 1234.`, n.String())
+}
+
+func TestClientStartListening(t *testing.T) {
+	mockConn := new(MockConn)
+	mockConn.On("WaitForNotification", mock.Anything).
+		Return(&pgconn.Notification{PID: 7, Channel: "jobs", Payload: "done"}, nil).Once()
+	mockConn.On("WaitForNotification", mock.Anything).Return(nil, nil)
+
+	client := &Client{
+		executor: &executor{Conn: mockConn},
+		logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+
+	received := make(chan Notification, 1)
+	client.StartListening(func(n Notification) { received <- n })
+	assert.Nil(t, client.listener, "no channels, nothing to wait for")
+
+	client.channels = []string{"jobs"}
+	client.StartListening(func(n Notification) { received <- n })
+	assert.Equal(t, Notification{Channel: "jobs", Payload: "done", PID: 7}, <-received)
+
+	client.StopListening()
+	assert.Nil(t, client.listener)
+	client.StopListening()
+}
+
+func TestClientStopListeningReturnsAtOnce(t *testing.T) {
+	server := startFakeServer(t, pingReplies)
+	client := server.connect(t)
+	client.channels = []string{"jobs"}
+
+	client.StartListening(func(Notification) {})
+	time.Sleep(50 * time.Millisecond) // let the wait reach the socket
+
+	start := time.Now()
+	client.StopListening()
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Empty(t, server.cancels, "an idle backend is not sent a cancel request")
+	assert.NoError(t, client.Ping(t.Context()))
+}
+
+func TestClientNotificationDuringQuery(t *testing.T) {
+	server := startFakeServer(t, func(msg pgproto3.FrontendMessage) []pgproto3.BackendMessage {
+		switch msg := msg.(type) {
+		case *pgproto3.Parse:
+			return []pgproto3.BackendMessage{&pgproto3.ParseComplete{}}
+		case *pgproto3.Describe:
+			columns := &pgproto3.RowDescription{Fields: []pgproto3.FieldDescription{
+				{Name: []byte("n"), DataTypeOID: 23, DataTypeSize: 4, TypeModifier: -1},
+			}}
+			if msg.ObjectType == 'S' {
+				return []pgproto3.BackendMessage{&pgproto3.ParameterDescription{}, columns}
+			}
+			return []pgproto3.BackendMessage{columns}
+		case *pgproto3.Bind:
+			return []pgproto3.BackendMessage{&pgproto3.BindComplete{}}
+		case *pgproto3.Execute:
+			return []pgproto3.BackendMessage{
+				&pgproto3.DataRow{Values: [][]byte{[]byte("1")}},
+				&pgproto3.NotificationResponse{PID: 7, Channel: "jobs", Payload: "done"},
+				&pgproto3.CommandComplete{CommandTag: []byte("SELECT 1")},
+			}
+		case *pgproto3.Sync:
+			return []pgproto3.BackendMessage{&pgproto3.ReadyForQuery{TxStatus: 'I'}}
+		}
+		return pingReplies(msg)
+	})
+	client := server.connect(t)
+
+	res, err := client.ExecuteQuery(t.Context(), "SELECT 1 AS n")
+	require.NoError(t, err)
+	rows, err := res.(*result.QueryResult).Rows()
+	require.NoError(t, err)
+	assert.Equal(t, [][]any{{int32(1)}}, rows)
+
+	var received []Notification
+	client.StartListening(func(n Notification) { received = append(received, n) })
+	assert.Equal(t, []Notification{{Channel: "jobs", Payload: "done", PID: 7}}, received,
+		"the notification is passed on once the result is done")
+	assert.Nil(t, client.listener, "no \\listen channels, nothing to wait for")
+
+	received = nil
+	client.StartListening(func(n Notification) { received = append(received, n) })
+	assert.Empty(t, received, "a notification is passed on once")
+}
+
+func TestClientListenerPassesOnNotifications(t *testing.T) {
+	server := startFakeServer(t, func(msg pgproto3.FrontendMessage) []pgproto3.BackendMessage {
+		if q, ok := msg.(*pgproto3.Query); ok && q.String == `LISTEN "jobs"` {
+			return []pgproto3.BackendMessage{
+				&pgproto3.CommandComplete{CommandTag: []byte("LISTEN")},
+				&pgproto3.ReadyForQuery{TxStatus: 'I'},
+				// sent while the session is idle
+				&pgproto3.NotificationResponse{PID: 7, Channel: "jobs", Payload: "done"},
+			}
+		}
+		return pingReplies(msg)
+	})
+	client := server.connect(t)
+	require.NoError(t, client.Listen(t.Context(), "jobs"))
+
+	received := make(chan Notification, 1)
+	client.StartListening(func(n Notification) { received <- n })
+	defer client.StopListening()
+
+	select {
+	case n := <-received:
+		assert.Equal(t, Notification{Channel: "jobs", Payload: "done", PID: 7}, n)
+	case <-time.After(time.Second):
+		t.Fatal("notification was not passed on")
+	}
+}
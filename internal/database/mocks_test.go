@@ -31,12 +31,34 @@ func (mc *MockConn) Ping(ctx context.Context) error {
 func (mc *MockConn) QueryRow(_ context.Context, _ string, _ ...any) pgx.Row { return nil }
 func (mc *MockConn) Close(_ context.Context) error                          { return nil }
 func (mc *MockConn) Config() *pgx.ConnConfig                                { return nil }
+func (mc *MockConn) PgConn() *pgconn.PgConn                                 { return nil }
+
+func (mc *MockConn) Prepare(ctx context.Context, name, sql string) (*pgconn.StatementDescription, error) {
+	argsMocks := mc.Called(ctx, name, sql)
+	sd, _ := argsMocks.Get(0).(*pgconn.StatementDescription)
+	return sd, argsMocks.Error(1)
+}
+
+func (mc *MockConn) Deallocate(ctx context.Context, name string) error {
+	argsMocks := mc.Called(ctx, name)
+	return argsMocks.Error(0)
+}
+
+func (mc *MockConn) WaitForNotification(ctx context.Context) (*pgconn.Notification, error) {
+	argsMocks := mc.Called(ctx)
+	if n, ok := argsMocks.Get(0).(*pgconn.Notification); ok {
+		return n, argsMocks.Error(1)
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
 
 type MockRows struct {
 	mock.Mock
 	data   [][]any
 	fields []pgconn.FieldDescription
 	tag    pgconn.CommandTag
+	err    error
 	index  int
 }
 
@@ -63,7 +85,7 @@ func (m *MockRows) Scan(dest ...any) error {
 
 func (m *MockRows) Conn() *pgx.Conn               { return &pgx.Conn{} }
 func (m *MockRows) Close()                        {}
-func (m *MockRows) Err() error                    { return nil }
+func (m *MockRows) Err() error                    { return m.err }
 func (m *MockRows) CommandTag() pgconn.CommandTag { return m.tag }
 func (m *MockRows) Values() ([]any, error) {
 	if m.index == 0 || m.index > len(m.data) {
@@ -0,0 +1,158 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/balaji01-4d/pgxcli/internal/database/result"
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/balaji01-4d/pgxspecial/database"
+)
+
+// listPreparedQuery lists the statements prepared on the session, both with
+// \parse and with SQL PREPARE.
+const listPreparedQuery = `SELECT name AS "Name",
+       pg_catalog.array_to_string(parameter_types, ', ') AS "Parameter types",
+       CASE WHEN from_sql THEN 'sql' ELSE 'protocol' END AS "Prepared by",
+       prepare_time AS "Prepare time",
+       statement AS "Statement"
+FROM pg_catalog.pg_prepared_statements
+ORDER BY name`
+
+// ParseAction asks to prepare the query buffer as a named statement.
+type ParseAction struct {
+	Name string
+}
+
+// ResultKind returns the special result kind for ParseAction.
+func (a ParseAction) ResultKind() pgxspecial.SpecialResultKind {
+	return Parse
+}
+
+// BindNamedAction executes a prepared statement with parameter values.
+type BindNamedAction struct {
+	Name   string
+	Values []string
+}
+
+// ResultKind returns the special result kind for BindNamedAction.
+func (a BindNamedAction) ResultKind() pgxspecial.SpecialResultKind {
+	return BindNamed
+}
+
+// ClosePreparedAction deallocates a prepared statement.
+type ClosePreparedAction struct {
+	Name string
+}
+
+// ResultKind returns the special result kind for ClosePreparedAction.
+func (a ClosePreparedAction) ResultKind() pgxspecial.SpecialResultKind {
+	return ClosePrepared
+}
+
+// parseStatementArgs splits args into a required statement name followed by
+// parameter values.
+func parseStatementArgs(cmd, args string) (string, []string, error) {
+	fields, err := splitArgs(args)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", cmd, err)
+	}
+	if len(fields) == 0 || fields[0] == "" {
+		return "", nil, fmt.Errorf("%s: statement name is required", cmd)
+	}
+	return fields[0], fields[1:], nil
+}
+
+func parseParseArgs(args string) (ParseAction, error) {
+	name, extra, err := parseStatementArgs("\\parse", args)
+	if err != nil {
+		return ParseAction{}, err
+	}
+	if len(extra) > 0 {
+		return ParseAction{}, errors.New("\\parse: too many arguments")
+	}
+	return ParseAction{Name: name}, nil
+}
+
+func parseBindNamedArgs(args string) (BindNamedAction, error) {
+	name, values, err := parseStatementArgs("\\bind_named", args)
+	if err != nil {
+		return BindNamedAction{}, err
+	}
+	return BindNamedAction{Name: name, Values: values}, nil
+}
+
+func parseClosePreparedArgs(args string) (ClosePreparedAction, error) {
+	name, extra, err := parseStatementArgs("\\close_prepared", args)
+	if err != nil {
+		return ClosePreparedAction{}, err
+	}
+	if len(extra) > 0 {
+		return ClosePreparedAction{}, errors.New("\\close_prepared: too many arguments")
+	}
+	return ClosePreparedAction{Name: name}, nil
+}
+
+func listPrepared(ctx context.Context, db database.Queryer) (pgxspecial.SpecialCommandResult, error) {
+	rows, err := db.Query(ctx, listPreparedQuery)
+	if err != nil {
+		return nil, err
+	}
+	return pgxspecial.RowResult{Rows: rows}, nil
+}
+
+// Prepare creates the named prepared statement name from sql using the
+// extended protocol.
+func (c *Client) Prepare(ctx context.Context, name, sql string) error {
+	if c.executor == nil {
+		return errors.New("not connected to any database")
+	}
+	sql = strings.TrimRight(strings.TrimSpace(sql), ";")
+	if sql == "" {
+		return errors.New("\\parse: query buffer is empty")
+	}
+
+	if _, err := c.executor.Conn.Prepare(ctx, name, sql); err != nil {
+		return c.RecoverConnection(ctx, err)
+	}
+	if !slices.Contains(c.prepared, name) {
+		c.prepared = append(c.prepared, name)
+	}
+	return nil
+}
+
+// ExecutePrepared runs a statement prepared with Prepare, binding values to
+// its parameters.
+func (c *Client) ExecutePrepared(ctx context.Context, name string, values []string) (result.Result, error) {
+	if c.executor == nil {
+		return nil, errors.New("not connected to any database")
+	}
+	if !slices.Contains(c.prepared, name) {
+		return nil, fmt.Errorf("\\bind_named: prepared statement %q does not exist, create it with \\parse", name)
+	}
+
+	args := make([]any, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	res, err := c.executor.executePrepared(ctx, name, args...)
+	if err != nil {
+		return nil, c.RecoverConnection(ctx, err)
+	}
+	return res, nil
+}
+
+// ClosePrepared deallocates the named prepared statement.
+func (c *Client) ClosePrepared(ctx context.Context, name string) error {
+	if c.executor == nil {
+		return errors.New("not connected to any database")
+	}
+	if err := c.executor.Conn.Deallocate(ctx, name); err != nil {
+		return c.RecoverConnection(ctx, err)
+	}
+	c.prepared = slices.DeleteFunc(c.prepared, func(n string) bool { return n == name })
+	return nil
+}
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePreparedStatementArgs(t *testing.T) {
+	parse, err := parseParseArgs(" by_id ")
+	require.NoError(t, err)
+	assert.Equal(t, ParseAction{Name: "by_id"}, parse)
+
+	_, err = parseParseArgs("")
+	assert.EqualError(t, err, "\\parse: statement name is required")
+
+	_, err = parseParseArgs("a b")
+	assert.EqualError(t, err, "\\parse: too many arguments")
+
+	bind, err := parseBindNamedArgs("by_id 42 'two words'")
+	require.NoError(t, err)
+	assert.Equal(t, BindNamedAction{Name: "by_id", Values: []string{"42", "two words"}}, bind)
+
+	bind, err = parseBindNamedArgs("no_params")
+	require.NoError(t, err)
+	assert.Equal(t, BindNamedAction{Name: "no_params", Values: []string{}}, bind)
+
+	_, err = parseBindNamedArgs("'unterminated")
+	assert.EqualError(t, err, "\\bind_named: unterminated quoted string")
+
+	closeAction, err := parseClosePreparedArgs("by_id")
+	require.NoError(t, err)
+	assert.Equal(t, ClosePreparedAction{Name: "by_id"}, closeAction)
+
+	_, err = parseClosePreparedArgs("")
+	assert.EqualError(t, err, "\\close_prepared: statement name is required")
+}
+
+func TestClientPreparedStatements(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	mockConn := new(MockConn)
+	mockConn.On("Prepare", ctx, "by_id", "select * from users where id = $1").
+		Return(&pgconn.StatementDescription{Name: "by_id"}, nil)
+	mockConn.On("Query", ctx, "by_id").Return(&MockRows{
+		fields: []pgconn.FieldDescription{{Name: "id"}},
+		data:   [][]any{{42}},
+	}, nil)
+	mockConn.On("Deallocate", ctx, "by_id").Return(nil)
+
+	client := &Client{executor: &executor{Conn: mockConn, Logger: logger}, logger: logger}
+
+	_, err := client.ExecutePrepared(ctx, "by_id", []string{"42"})
+	assert.ErrorContains(t, err, `prepared statement "by_id" does not exist`)
+
+	assert.EqualError(t, client.Prepare(ctx, "by_id", "  ;"), "\\parse: query buffer is empty")
+
+	require.NoError(t, client.Prepare(ctx, "by_id", "select * from users where id = $1;"))
+	require.NoError(t, client.Prepare(ctx, "by_id", "select * from users where id = $1"))
+	assert.Equal(t, []string{"by_id"}, client.prepared)
+
+	res, err := client.ExecutePrepared(ctx, "by_id", []string{"42"})
+	require.NoError(t, err)
+	assert.NotNil(t, res)
+
+	require.NoError(t, client.ClosePrepared(ctx, "by_id"))
+	assert.Empty(t, client.prepared)
+	mockConn.AssertExpectations(t)
+	mockConn.AssertCalled(t, "Query", ctx, "by_id")
+	mockConn.AssertNotCalled(t, "Query", mock.Anything, "select * from users where id = $1")
+}
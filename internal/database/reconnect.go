@@ -0,0 +1,211 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/balaji01-4d/pgxcli/internal/parser"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ReconnectError reports that the connection was lost while running a
+// statement. The failed statement is never re-run.
+type ReconnectError struct {
+	// Err is the failure that revealed the lost connection.
+	Err error
+	// ReconnectErr is set when the connection could not be re-established.
+	ReconnectErr error
+	// LostTransaction is true when an open transaction block was rolled back.
+	LostTransaction bool
+	// FailedOver names the host reconnected to when it is not the host that
+	// was lost, as when a multi-host connection fails over to a standby.
+	FailedOver string
+}
+
+func (e *ReconnectError) Error() string {
+	var msg string
+	switch {
+	case e.NeedsPassword():
+		msg = "connection lost, password required to reconnect"
+	case e.ReconnectErr != nil:
+		msg = fmt.Sprintf("connection lost, reconnect failed: %v", e.ReconnectErr)
+	case e.FailedOver != "":
+		msg = fmt.Sprintf("connection lost, reconnected to host %q; the failed statement was not re-run", e.FailedOver)
+	default:
+		msg = "connection lost, reconnected; the failed statement was not re-run"
+	}
+	if e.LostTransaction {
+		msg += " (the open transaction was rolled back)"
+	}
+	return msg
+}
+
+func (e *ReconnectError) Unwrap() []error {
+	errs := []error{e.Err}
+	if e.ReconnectErr != nil {
+		errs = append(errs, e.ReconnectErr)
+	}
+	return errs
+}
+
+// NeedsPassword reports whether reconnecting failed for lack of a valid password.
+func (e *ReconnectError) NeedsPassword() bool {
+	return isAuthError(e.ReconnectErr)
+}
+
+func isAuthError(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "28P01" // invalid_password
+}
+
+// connectionLost reports whether err means the server connection is gone.
+func (e *executor) connectionLost(err error) bool {
+	if err == nil || e.Conn == nil {
+		return false
+	}
+	if pgConn := e.Conn.PgConn(); pgConn != nil && pgConn.IsClosed() {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "57P01", "57P02", "57P03": // admin_shutdown, crash_shutdown, cannot_connect_now
+			return true
+		}
+		return strings.HasPrefix(pgErr.Code, "08") // connection_exception class
+	}
+	return false
+}
+
+// RecoverConnection checks whether err was caused by a lost connection and,
+// if so, reconnects. err is returned unchanged when the connection is fine;
+// otherwise a *ReconnectError describes the outcome.
+func (c *Client) RecoverConnection(ctx context.Context, err error) error {
+	if c.executor == nil || !c.executor.connectionLost(err) {
+		return err
+	}
+
+	status := c.Status().TxStatus
+	lostHost, lostPort := c.executor.Host, c.executor.Port
+	c.logger.Warn("Connection lost, reconnecting", "error", err)
+
+	recErr := &ReconnectError{
+		Err:             err,
+		LostTransaction: status == TxActive || status == TxFailed,
+	}
+	if recErr.ReconnectErr = c.reconnect(ctx, ""); recErr.ReconnectErr != nil {
+		c.logger.Error("Reconnect failed", "error", recErr.ReconnectErr)
+	} else if c.executor.Host != lostHost || c.executor.Port != lostPort {
+		recErr.FailedOver = net.JoinHostPort(c.executor.Host, strconv.Itoa(int(c.executor.Port)))
+	}
+	return recErr
+}
+
+// Reconnect re-establishes the current session using password, typically
+// after RecoverConnection reported that a password is required.
+func (c *Client) Reconnect(ctx context.Context, password string) error {
+	if c.executor == nil {
+		return fmt.Errorf("not connected to any database")
+	}
+	return c.reconnect(ctx, password)
+}
+
+// AwaitingPassword reports whether the last reconnect attempt was rejected
+// for lack of a valid password.
+func (c *Client) AwaitingPassword() bool {
+	return c.awaitingPassword
+}
+
+// reconnect opens a new connection with the current connection settings,
+// switching to the current database and replaying session SET statements.
+// The hosts of a multi-host connection are tried again from the first, so
+// target_session_attrs picks the host anew.
+// An empty password keeps the password of the lost connection.
+func (c *Client) reconnect(ctx context.Context, password string) error {
+	oldConfig := c.executor.Conn.Config()
+	if oldConfig == nil {
+		return fmt.Errorf("connection settings are unavailable")
+	}
+
+	connConfig := oldConfig.Copy()
+	connConfig.Database = c.currentDB
+	if password != "" {
+		connConfig.Password = password
+	}
+
+	exec, err := newExecutor(ctx, &pgConnector{cfg: connConfig}, c.logger)
+	if err != nil {
+		c.awaitingPassword = isAuthError(err)
+		return err
+	}
+
+	oldExecutor := c.executor
+	c.executor = exec
+	c.currentDB = exec.Database
+	c.awaitingPassword = false
+	c.prepared = nil   // prepared statements do not survive the session
+	c.txSettings = nil // nor does the transaction they were made in
+	if err := oldExecutor.close(ctx); err != nil {
+		c.logger.Debug("Closing lost connection failed", "error", err)
+	}
+
+	for _, name := range c.settingOrder {
+		stmt := c.settings[name]
+		if _, err := exec.Conn.Exec(ctx, stmt); err != nil {
+			c.logger.Warn("Failed to restore session setting", "setting", name, "error", err)
+		}
+	}
+
+	for _, channel := range c.channels {
+		if _, err := exec.Conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+			c.logger.Warn("Failed to listen again", "channel", channel, "error", err)
+		}
+	}
+
+	c.logger.Info("Reconnected", "database", exec.Database, "restored_settings", len(c.settingOrder))
+	return nil
+}
+
+// trackSetting remembers session-level SET statements so they can be
+// replayed after a reconnect, and forgets them on RESET.
+func (c *Client) trackSetting(stmt string) {
+	change, ok := parser.ParseSettingChange(stmt)
+	if !ok {
+		return
+	}
+
+	if change.Reset && change.Name == "" {
+		c.clearSettings()
+		return
+	}
+
+	if _, exists := c.settings[change.Name]; exists {
+		for i, name := range c.settingOrder {
+			if name == change.Name {
+				c.settingOrder = append(c.settingOrder[:i], c.settingOrder[i+1:]...)
+				break
+			}
+		}
+		delete(c.settings, change.Name)
+	}
+	if change.Reset {
+		return
+	}
+
+	if c.settings == nil {
+		c.settings = make(map[string]string)
+	}
+	c.settings[change.Name] = stmt
+	c.settingOrder = append(c.settingOrder, change.Name)
+}
+
+func (c *Client) clearSettings() {
+	c.settings = nil
+	c.settingOrder = nil
+}
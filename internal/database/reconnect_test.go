@@ -0,0 +1,205 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/balaji01-4d/pgxcli/internal/database/result"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutorConnectionLost(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil error", err: nil, want: false},
+		{name: "plain error", err: errors.New("boom"), want: false},
+		{name: "syntax error", err: &pgconn.PgError{Code: "42601"}, want: false},
+		{name: "admin shutdown", err: &pgconn.PgError{Code: "57P01"}, want: true},
+		{name: "crash shutdown", err: &pgconn.PgError{Code: "57P02"}, want: true},
+		{name: "connection failure", err: &pgconn.PgError{Code: "08006"}, want: true},
+	}
+
+	exec := &executor{Conn: new(MockConn)}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, exec.connectionLost(tc.err))
+		})
+	}
+}
+
+func TestReconnectErrorMessage(t *testing.T) {
+	lost := &pgconn.PgError{Code: "57P01"}
+
+	testCases := []struct {
+		name         string
+		err          *ReconnectError
+		wantContains string
+		needsPass    bool
+	}{
+		{
+			name:         "reconnected",
+			err:          &ReconnectError{Err: lost},
+			wantContains: "reconnected; the failed statement was not re-run",
+		},
+		{
+			name:         "failed over",
+			err:          &ReconnectError{Err: lost, FailedOver: "standby:5432"},
+			wantContains: `reconnected to host "standby:5432"; the failed statement was not re-run`,
+		},
+		{
+			name:         "reconnect failed",
+			err:          &ReconnectError{Err: lost, ReconnectErr: errors.New("refused")},
+			wantContains: "reconnect failed: refused",
+		},
+		{
+			name:         "password required",
+			err:          &ReconnectError{Err: lost, ReconnectErr: &pgconn.PgError{Code: "28P01"}},
+			wantContains: "password required to reconnect",
+			needsPass:    true,
+		},
+		{
+			name:         "transaction lost",
+			err:          &ReconnectError{Err: lost, LostTransaction: true},
+			wantContains: "open transaction was rolled back",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Contains(t, tc.err.Error(), tc.wantContains)
+			assert.Equal(t, tc.needsPass, tc.err.NeedsPassword())
+			assert.ErrorIs(t, tc.err, lost)
+		})
+	}
+}
+
+func TestRecoverConnection_PassesThroughOtherErrors(t *testing.T) {
+	client := &Client{executor: &executor{Conn: new(MockConn)}}
+	queryErr := &pgconn.PgError{Code: "42P01"}
+
+	err := client.RecoverConnection(context.Background(), queryErr)
+	assert.Same(t, queryErr, err)
+}
+
+func TestClientTrackSetting(t *testing.T) {
+	client := &Client{}
+
+	client.trackSetting("SET search_path TO app")
+	client.trackSetting("SET work_mem = '64MB'")
+	client.trackSetting("SELECT 1")
+	client.trackSetting("SET LOCAL statement_timeout = 0")
+	client.trackSetting("set search_path to app, public")
+
+	require.Equal(t, []string{"work_mem", "search_path"}, client.settingOrder)
+	assert.Equal(t, "set search_path to app, public", client.settings["search_path"])
+
+	client.trackSetting("RESET work_mem")
+	assert.Equal(t, []string{"search_path"}, client.settingOrder)
+
+	client.trackSetting("RESET ALL")
+	assert.Empty(t, client.settingOrder)
+	assert.Empty(t, client.settings)
+}
+
+func TestClientExecuteQuery_TracksSucceededSettingsOnly(t *testing.T) {
+	ctx := context.Background()
+	const (
+		good = "SET work_mem = '64MB'"
+		bad  = "SET work_mem = 'lots'"
+	)
+	badValue := &pgconn.PgError{Code: "22023", Message: `invalid value for parameter "work_mem": "lots"`}
+
+	conn := new(MockConn)
+	conn.On("Query", ctx, good).Return(&MockRows{tag: pgconn.NewCommandTag("SET")}, nil)
+	conn.On("Query", ctx, bad).Return(&MockRows{err: badValue}, nil)
+	client := &Client{executor: &executor{Conn: conn, Logger: slog.Default()}, autocommit: true}
+
+	_, err := client.ExecuteQuery(ctx, good)
+	require.NoError(t, err)
+	_, err = client.ExecuteQuery(ctx, bad)
+	assert.ErrorIs(t, err, badValue)
+
+	assert.Equal(t, []string{"work_mem"}, client.settingOrder)
+	assert.Equal(t, good, client.settings["work_mem"])
+}
+
+// txServer answers statements with their command tag, following the
+// transaction status through BEGIN, COMMIT and ROLLBACK as a server would.
+func txServer() func(pgproto3.FrontendMessage) []pgproto3.BackendMessage {
+	txStatus := byte('I')
+	var sql string
+	complete := func() pgproto3.BackendMessage {
+		tag := strings.ToUpper(strings.Fields(sql)[0])
+		switch tag {
+		case "BEGIN":
+			txStatus = 'T'
+		case "COMMIT", "ROLLBACK":
+			txStatus = 'I'
+		}
+		return &pgproto3.CommandComplete{CommandTag: []byte(tag)}
+	}
+
+	return func(msg pgproto3.FrontendMessage) []pgproto3.BackendMessage {
+		switch msg := msg.(type) {
+		case *pgproto3.Query:
+			sql = msg.String
+			if strings.TrimSpace(sql) == "-- ping" {
+				return []pgproto3.BackendMessage{&pgproto3.EmptyQueryResponse{}, &pgproto3.ReadyForQuery{TxStatus: txStatus}}
+			}
+			return []pgproto3.BackendMessage{complete(), &pgproto3.ReadyForQuery{TxStatus: txStatus}}
+		case *pgproto3.Parse:
+			sql = msg.Query
+			return []pgproto3.BackendMessage{&pgproto3.ParseComplete{}}
+		case *pgproto3.Describe:
+			if msg.ObjectType == 'S' {
+				return []pgproto3.BackendMessage{&pgproto3.ParameterDescription{}, &pgproto3.NoData{}}
+			}
+			return []pgproto3.BackendMessage{&pgproto3.NoData{}}
+		case *pgproto3.Bind:
+			return []pgproto3.BackendMessage{&pgproto3.BindComplete{}}
+		case *pgproto3.Execute:
+			return []pgproto3.BackendMessage{complete()}
+		case *pgproto3.Sync:
+			return []pgproto3.BackendMessage{&pgproto3.ReadyForQuery{TxStatus: txStatus}}
+		}
+		return nil
+	}
+}
+
+func TestClientExecuteQuery_TracksSettingsOfCommittedTransactions(t *testing.T) {
+	client := startFakeServer(t, txServer()).connect(t)
+	run := func(stmts ...string) {
+		for _, stmt := range stmts {
+			res, err := client.ExecuteQuery(t.Context(), stmt)
+			require.NoError(t, err, stmt)
+			_, err = res.(*result.QueryResult).Rows() // read as the app does
+			require.NoError(t, err, stmt)
+		}
+	}
+
+	run("BEGIN", "SET work_mem = '64MB'")
+	assert.Empty(t, client.settingOrder, "kept only once the transaction commits")
+	run("ROLLBACK")
+	assert.Empty(t, client.settingOrder, "rolled back with the transaction")
+	assert.Empty(t, client.txSettings)
+
+	run("BEGIN", "SET search_path TO app", "COMMIT")
+	assert.Equal(t, []string{"search_path"}, client.settingOrder)
+
+	client.SetAutocommit(false)
+	run("SET statement_timeout = 0")
+	assert.Equal(t, []string{"search_path"}, client.settingOrder, "inside the implicit BEGIN")
+	run("COMMIT")
+	assert.Equal(t, []string{"search_path", "statement_timeout"}, client.settingOrder)
+}
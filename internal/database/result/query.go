@@ -11,6 +11,10 @@ import (
 // QueryResult represents a row-producing SQL execution result.
 type QueryResult struct {
 	rowStreamer
+
+	// buffered holds the rows read by Materialize.
+	buffered     [][]any
+	materialized bool
 }
 
 func NewQuery(rows pgx.Rows, duration time.Duration) *QueryResult {
@@ -38,6 +42,9 @@ func (r *QueryResult) Columns() []string {
 }
 
 func (r *QueryResult) Rows() ([][]any, error) {
+	if r.materialized {
+		return r.buffered, nil
+	}
 	collected := make([][]any, 0, 256)
 	for {
 		row, err := r.Next()
@@ -54,6 +61,22 @@ func (r *QueryResult) Rows() ([][]any, error) {
 	return collected, nil
 }
 
+// Materialize reads all remaining rows into memory and releases the
+// connection, so any error the server reports for the statement surfaces here.
+func (r *QueryResult) Materialize() error {
+	if r.materialized {
+		return nil
+	}
+	r.Columns() // field descriptions must be captured before the rows close
+	rows, err := r.Rows()
+	if err != nil {
+		return err
+	}
+	r.buffered = rows
+	r.materialized = true
+	return nil
+}
+
 func (r *QueryResult) Caption() string { return "" } // TODO: add execution time or row count
 
 type rowStreamer struct {
@@ -112,6 +135,12 @@ func (r *QueryResult) CommandTag() string {
 	return r.rows.CommandTag().String()
 }
 
+// RowsAffected returns the number of rows returned or affected, as reported
+// by the command tag once the rows are read.
+func (r *QueryResult) RowsAffected() int64 {
+	return r.rows.CommandTag().RowsAffected()
+}
+
 func convertValue(v any) any {
 	switch val := v.(type) {
 	case pgtype.Numeric:
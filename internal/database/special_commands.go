@@ -2,6 +2,11 @@ package database
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/balaji01-4d/pgxspecial"
 	"github.com/balaji01-4d/pgxspecial/database"
@@ -16,8 +21,33 @@ const (
 	ChangeDB
 	// Conninfo is the result kind for connection info command actions.
 	Conninfo
+	// Watch is the result kind for repeated query execution actions.
+	Watch
+	// Copy is the result kind for client-side \copy actions.
+	Copy
+	// Import is the result kind for CSV import actions.
+	Import
+	// Listen is the result kind for \listen and \unlisten actions.
+	Listen
+	// Bind is the result kind for \bind actions.
+	Bind
+	// Parse is the result kind for \parse actions.
+	Parse
+	// BindNamed is the result kind for \bind_named actions.
+	BindNamed
+	// ClosePrepared is the result kind for \close_prepared actions.
+	ClosePrepared
+	// Explain is the result kind for \explain plans.
+	Explain
+	// History is the result kind for \history listings.
+	History
+	// Config is the result kind for \config actions.
+	Config
 )
 
+// defaultWatchInterval matches psql's default \watch interval.
+const defaultWatchInterval = 2 * time.Second
+
 func init() {
 	registerSpecialCommands()
 }
@@ -54,6 +84,136 @@ func registerSpecialCommands() {
 		},
 		CaseSensitive: false,
 	})
+
+	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:         "\\watch",
+		Syntax:      "\\watch [SEC] [COUNT]",
+		Description: "Execute the previous query every SEC seconds, optionally COUNT times",
+		Handler: func(_ context.Context, _ database.Queryer, s string, _ bool) (pgxspecial.SpecialCommandResult, error) {
+			return parseWatchArgs(s)
+		},
+		CaseSensitive: false,
+	})
+
+	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:         "\\copy",
+		Syntax:      "\\copy table [(cols)] from|to 'file'|stdin|stdout [with (options)]",
+		Description: "Copy data between a local file and a table, streamed by the client",
+		Handler: func(_ context.Context, _ database.Queryer, s string, _ bool) (pgxspecial.SpecialCommandResult, error) {
+			return parseCopyArgs(s)
+		},
+		CaseSensitive: false,
+	})
+
+	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:         "\\import",
+		Syntax:      "\\import file.csv [table]",
+		Description: "Create a table with types inferred from a CSV file and load it",
+		Handler: func(_ context.Context, _ database.Queryer, s string, _ bool) (pgxspecial.SpecialCommandResult, error) {
+			return parseImportArgs(s)
+		},
+		CaseSensitive: false,
+	})
+
+	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:         "\\listen",
+		Syntax:      "\\listen channel",
+		Description: "Listen for notifications on a channel",
+		Handler: func(_ context.Context, _ database.Queryer, s string, _ bool) (pgxspecial.SpecialCommandResult, error) {
+			return parseListenArgs(s, true)
+		},
+		CaseSensitive: false,
+	})
+
+	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:         "\\unlisten",
+		Syntax:      "\\unlisten [channel|*]",
+		Description: "Stop listening for notifications on a channel, or on all channels",
+		Handler: func(_ context.Context, _ database.Queryer, s string, _ bool) (pgxspecial.SpecialCommandResult, error) {
+			return parseListenArgs(s, false)
+		},
+		CaseSensitive: false,
+	})
+
+	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:         "\\bind",
+		Syntax:      "\\bind [PARAM]...",
+		Description: "Set query parameters for the next statement",
+		Handler: func(_ context.Context, _ database.Queryer, s string, _ bool) (pgxspecial.SpecialCommandResult, error) {
+			return parseBindArgs(s)
+		},
+		CaseSensitive: false,
+	})
+
+	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:         "\\parse",
+		Syntax:      "\\parse STMT_NAME",
+		Description: "Create a prepared statement from the query before it, or the previous query",
+		Handler: func(_ context.Context, _ database.Queryer, s string, _ bool) (pgxspecial.SpecialCommandResult, error) {
+			return parseParseArgs(s)
+		},
+		CaseSensitive: false,
+	})
+
+	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:         "\\bind_named",
+		Syntax:      "\\bind_named STMT_NAME [PARAM]...",
+		Description: "Execute a prepared statement with the given parameters",
+		Handler: func(_ context.Context, _ database.Queryer, s string, _ bool) (pgxspecial.SpecialCommandResult, error) {
+			return parseBindNamedArgs(s)
+		},
+		CaseSensitive: false,
+	})
+
+	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:         "\\close_prepared",
+		Syntax:      "\\close_prepared STMT_NAME",
+		Description: "Deallocate a prepared statement",
+		Handler: func(_ context.Context, _ database.Queryer, s string, _ bool) (pgxspecial.SpecialCommandResult, error) {
+			return parseClosePreparedArgs(s)
+		},
+		CaseSensitive: false,
+	})
+
+	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:         "\\prepared",
+		Syntax:      "\\prepared",
+		Description: "List prepared statements of the session",
+		Handler: func(ctx context.Context, db database.Queryer, _ string, _ bool) (pgxspecial.SpecialCommandResult, error) {
+			return listPrepared(ctx, db)
+		},
+		CaseSensitive: false,
+	})
+
+	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:         "\\explain",
+		Syntax:      "\\explain [analyze] QUERY | diff",
+		Description: "Show the query plan as a tree, or compare the last two plans",
+		Handler: func(ctx context.Context, db database.Queryer, s string, _ bool) (pgxspecial.SpecialCommandResult, error) {
+			return explain(ctx, db, s)
+		},
+		CaseSensitive: false,
+	})
+
+	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:         "\\history",
+		Syntax:      "\\history [-g] [pattern]",
+		Description: "List past queries with when, where and how they ran (-g: from all connections)",
+		Handler: func(_ context.Context, _ database.Queryer, s string, _ bool) (pgxspecial.SpecialCommandResult, error) {
+			return parseHistoryArgs(s), nil
+		},
+		CaseSensitive: false,
+	})
+
+	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:         "\\config",
+		Syntax:      "\\config [key [value]]",
+		Description: "Show settings, or change one for this session only",
+		Handler: func(_ context.Context, _ database.Queryer, s string, _ bool) (pgxspecial.SpecialCommandResult, error) {
+			return parseConfigArgs(s), nil
+		},
+		CaseSensitive: false,
+	})
 }
 
 // ExitAction indicates that the REPL should terminate.
@@ -81,3 +241,125 @@ type ConnInfoAction struct{}
 func (g ConnInfoAction) ResultKind() pgxspecial.SpecialResultKind {
 	return Conninfo
 }
+
+// HistoryAction asks for the history entries whose query contains Pattern,
+// or all of them when it is empty. Global lists the entries of every
+// connection regardless of history_scope.
+type HistoryAction struct {
+	Pattern string
+	Global  bool
+}
+
+// ResultKind returns the special result kind for HistoryAction.
+func (h HistoryAction) ResultKind() pgxspecial.SpecialResultKind {
+	return History
+}
+
+// parseHistoryArgs splits \history arguments into the optional -g flag and
+// the pattern.
+func parseHistoryArgs(args string) HistoryAction {
+	args = strings.TrimSpace(args)
+	first, rest, _ := strings.Cut(args, " ")
+	if first == "-g" {
+		return HistoryAction{Pattern: strings.TrimSpace(rest), Global: true}
+	}
+	return HistoryAction{Pattern: args}
+}
+
+// ConfigAction shows the live settings, or the one named Key, or changes
+// Key to Value for the session when Set.
+type ConfigAction struct {
+	Key   string
+	Value string
+	Set   bool
+}
+
+// ResultKind returns the special result kind for ConfigAction.
+func (c ConfigAction) ResultKind() pgxspecial.SpecialResultKind {
+	return Config
+}
+
+// parseConfigArgs splits \config arguments into the key and the value. The
+// value is the rest of the line; quotes around it are removed, so that it
+// can keep leading or trailing spaces.
+func parseConfigArgs(args string) ConfigAction {
+	key, value, set := strings.Cut(strings.TrimSpace(args), " ")
+	if !set {
+		return ConfigAction{Key: key}
+	}
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		value = value[1 : len(value)-1]
+	}
+	return ConfigAction{Key: key, Value: value, Set: true}
+}
+
+// WatchAction carries the interval and optional iteration count for \watch.
+// A zero Count means the query repeats until interrupted.
+type WatchAction struct {
+	Interval time.Duration
+	Count    int
+}
+
+// ResultKind returns the special result kind for WatchAction.
+func (w WatchAction) ResultKind() pgxspecial.SpecialResultKind {
+	return Watch
+}
+
+// parseWatchArgs accepts both the positional form (\watch 5 10) and
+// psql's named form (\watch interval=5 count=10, or i=5 c=10).
+// parseWatchInterval parses a \watch interval in seconds. Values that are
+// not finite, or do not fit a time.Duration, are rejected, as is an interval
+// too short to wait at all.
+func parseWatchInterval(value string) (time.Duration, error) {
+	secs, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsInf(secs, 0) || math.IsNaN(secs) || secs <= 0 ||
+		secs > float64(math.MaxInt64)/float64(time.Second) {
+		return 0, fmt.Errorf("\\watch: incorrect interval value %q", value)
+	}
+	interval := time.Duration(secs * float64(time.Second))
+	if interval <= 0 {
+		return 0, fmt.Errorf("\\watch: incorrect interval value %q", value)
+	}
+	return interval, nil
+}
+
+func parseWatchArgs(args string) (WatchAction, error) {
+	action := WatchAction{Interval: defaultWatchInterval}
+
+	positional := 0
+	for _, field := range strings.Fields(args) {
+		name, value, named := strings.Cut(field, "=")
+		if !named {
+			value = field
+			switch positional {
+			case 0:
+				name = "interval"
+			case 1:
+				name = "count"
+			default:
+				return WatchAction{}, fmt.Errorf("\\watch: too many arguments")
+			}
+			positional++
+		}
+
+		switch strings.ToLower(name) {
+		case "i", "interval":
+			interval, err := parseWatchInterval(value)
+			if err != nil {
+				return WatchAction{}, err
+			}
+			action.Interval = interval
+		case "c", "count":
+			count, err := strconv.Atoi(value)
+			if err != nil || count <= 0 {
+				return WatchAction{}, fmt.Errorf("\\watch: incorrect count value %q", value)
+			}
+			action.Count = count
+		default:
+			return WatchAction{}, fmt.Errorf("\\watch: unrecognized parameter %q", name)
+		}
+	}
+
+	return action, nil
+}
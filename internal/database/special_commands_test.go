@@ -0,0 +1,145 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWatchArgs(t *testing.T) {
+	testCases := []struct {
+		name    string
+		args    string
+		want    WatchAction
+		wantErr string
+	}{
+		{
+			name: "defaults when empty",
+			args: "",
+			want: WatchAction{Interval: 2 * time.Second},
+		},
+		{
+			name: "positional interval",
+			args: "5",
+			want: WatchAction{Interval: 5 * time.Second},
+		},
+		{
+			name: "fractional interval",
+			args: "0.5",
+			want: WatchAction{Interval: 500 * time.Millisecond},
+		},
+		{
+			name: "positional interval and count",
+			args: "1 3",
+			want: WatchAction{Interval: time.Second, Count: 3},
+		},
+		{
+			name: "named parameters",
+			args: "count=4 interval=10",
+			want: WatchAction{Interval: 10 * time.Second, Count: 4},
+		},
+		{
+			name: "short named parameters",
+			args: "i=3 c=2",
+			want: WatchAction{Interval: 3 * time.Second, Count: 2},
+		},
+		{
+			name:    "rejects non numeric interval",
+			args:    "abc",
+			wantErr: "incorrect interval value",
+		},
+		{
+			name:    "rejects zero interval",
+			args:    "0",
+			wantErr: "incorrect interval value",
+		},
+		{
+			name:    "rejects infinite interval",
+			args:    "inf",
+			wantErr: "incorrect interval value",
+		},
+		{
+			name:    "rejects NaN interval",
+			args:    "interval=nan",
+			wantErr: "incorrect interval value",
+		},
+		{
+			name:    "rejects interval overflowing a duration",
+			args:    "1e10",
+			wantErr: "incorrect interval value",
+		},
+		{
+			name:    "rejects interval shorter than a nanosecond",
+			args:    "1e-12",
+			wantErr: "incorrect interval value",
+		},
+		{
+			name:    "rejects negative count",
+			args:    "2 -1",
+			wantErr: "incorrect count value",
+		},
+		{
+			name:    "rejects unknown parameter",
+			args:    "every=2",
+			wantErr: "unrecognized parameter",
+		},
+		{
+			name:    "rejects extra arguments",
+			args:    "1 2 3",
+			wantErr: "too many arguments",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseWatchArgs(tc.args)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestParseHistoryArgs(t *testing.T) {
+	testCases := []struct {
+		args string
+		want HistoryAction
+	}{
+		{"", HistoryAction{}},
+		{"  users ", HistoryAction{Pattern: "users"}},
+		{"delete from", HistoryAction{Pattern: "delete from"}},
+		{"-g", HistoryAction{Global: true}},
+		{"-g  drop table", HistoryAction{Pattern: "drop table", Global: true}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.args, func(t *testing.T) {
+			assert.Equal(t, tc.want, parseHistoryArgs(tc.args))
+		})
+	}
+}
+
+func TestParseConfigArgs(t *testing.T) {
+	testCases := []struct {
+		args string
+		want ConfigAction
+	}{
+		{"", ConfigAction{}},
+		{" table.style ", ConfigAction{Key: "table.style"}},
+		{"table.style markdown", ConfigAction{Key: "table.style", Value: "markdown", Set: true}},
+		{`prompt "\u@\h> "`, ConfigAction{Key: "prompt", Value: `\u@\h> `, Set: true}},
+		{`prompt '\d=# '`, ConfigAction{Key: "prompt", Value: `\d=# `, Set: true}},
+		{`prompt \u on \d> `, ConfigAction{Key: "prompt", Value: `\u on \d>`, Set: true}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.args, func(t *testing.T) {
+			assert.Equal(t, tc.want, parseConfigArgs(tc.args))
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package database
+
+import (
+	"crypto/tls"
+	"strings"
+)
+
+// TxStatus is the transaction state reported by the server after each query.
+type TxStatus int
+
+const (
+	// TxUnknown means there is no connection or the state was not reported.
+	TxUnknown TxStatus = iota
+	// TxIdle means no transaction block is open.
+	TxIdle
+	// TxActive means a transaction block is open.
+	TxActive
+	// TxFailed means the open transaction block has failed and must be rolled back.
+	TxFailed
+)
+
+// txStatusFromByte maps the ReadyForQuery status indicator to a TxStatus.
+func txStatusFromByte(b byte) TxStatus {
+	switch b {
+	case 'I':
+		return TxIdle
+	case 'T':
+		return TxActive
+	case 'E':
+		return TxFailed
+	default:
+		return TxUnknown
+	}
+}
+
+// String returns a human-readable transaction state.
+func (s TxStatus) String() string {
+	switch s {
+	case TxIdle:
+		return "idle"
+	case TxActive:
+		return "in transaction"
+	case TxFailed:
+		return "failed transaction"
+	default:
+		return "unknown"
+	}
+}
+
+// Marker returns the psql-style prompt marker: "*" inside a transaction,
+// "!" in a failed transaction and an empty string otherwise.
+func (s TxStatus) Marker() string {
+	switch s {
+	case TxActive:
+		return "*"
+	case TxFailed:
+		return "!"
+	default:
+		return ""
+	}
+}
+
+// SessionStatus is a snapshot of the live session state of a connection.
+type SessionStatus struct {
+	TxStatus      TxStatus
+	PID           uint32
+	ServerVersion string
+	Superuser     bool
+	ReadOnly      bool
+}
+
+func (e *executor) status() SessionStatus {
+	if e.Conn == nil {
+		return SessionStatus{}
+	}
+	pgConn := e.Conn.PgConn()
+	if pgConn == nil {
+		return SessionStatus{}
+	}
+
+	// server_version may carry a distribution suffix, e.g. "16.2 (Debian 16.2-1)".
+	version, _, _ := strings.Cut(pgConn.ParameterStatus("server_version"), " ")
+
+	return SessionStatus{
+		TxStatus:      txStatusFromByte(pgConn.TxStatus()),
+		PID:           pgConn.PID(),
+		ServerVersion: version,
+		Superuser:     pgConn.ParameterStatus("is_superuser") == "on",
+		ReadOnly: pgConn.ParameterStatus("default_transaction_read_only") == "on" ||
+			pgConn.ParameterStatus("in_hot_standby") == "on",
+	}
+}
+
+// TLSStatus describes the TLS session of a connection.
+type TLSStatus struct {
+	// Version is the negotiated protocol, e.g. "TLS 1.3".
+	Version string
+	Cipher  string
+	// Verification tells how far the server certificate was checked.
+	Verification string
+}
+
+// TLS returns the TLS session of the current connection, and false when the
+// connection is not encrypted.
+func (c *Client) TLS() (TLSStatus, bool) {
+	if c.executor == nil || c.executor.Conn == nil {
+		return TLSStatus{}, false
+	}
+	pgConn := c.executor.Conn.PgConn()
+	if pgConn == nil {
+		return TLSStatus{}, false
+	}
+	conn, ok := pgConn.Conn().(*tls.Conn)
+	if !ok {
+		return TLSStatus{}, false
+	}
+	return tlsStatus(conn.ConnectionState(), c.executor.Conn.Config().TLSConfig), true
+}
+
+// tlsStatus describes state, verified according to cfg. pgconn checks the
+// chain itself, without the host name, for sslmode=verify-ca and skips
+// verification altogether below it.
+func tlsStatus(state tls.ConnectionState, cfg *tls.Config) TLSStatus {
+	verification := "not verified"
+	switch {
+	case cfg == nil:
+	case !cfg.InsecureSkipVerify:
+		verification = "verified"
+	case cfg.VerifyPeerCertificate != nil:
+		verification = "verified, host name not checked"
+	}
+	return TLSStatus{
+		Version:      tls.VersionName(state.Version),
+		Cipher:       tls.CipherSuiteName(state.CipherSuite),
+		Verification: verification,
+	}
+}
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxStatusFromByte(t *testing.T) {
+	testCases := []struct {
+		name       string
+		indicator  byte
+		want       TxStatus
+		wantMarker string
+	}{
+		{name: "idle", indicator: 'I', want: TxIdle, wantMarker: ""},
+		{name: "in transaction", indicator: 'T', want: TxActive, wantMarker: "*"},
+		{name: "failed transaction", indicator: 'E', want: TxFailed, wantMarker: "!"},
+		{name: "unknown", indicator: 0, want: TxUnknown, wantMarker: ""},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got := txStatusFromByte(tc.indicator)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.wantMarker, got.Marker())
+			assert.Equal(t, tc.name, got.String())
+		})
+	}
+}
+
+func TestParsePrompt_SessionEscapesWithoutSession(t *testing.T) {
+	client := New(slog.Default())
+	client.executor = &executor{
+		User:     "alice",
+		Host:     "db.example.com",
+		Database: "app",
+		Port:     5433,
+		Conn:     new(MockConn),
+		Logger:   slog.Default(),
+	}
+	client.currentDB = "app"
+
+	got := client.ParsePrompt(`\u@\h:\p/\d [\i \V]\x\r\#`)
+	assert.Equal(t, "alice@db:5433/app [(nil) (nil)]>", got)
+}
+
+func TestTLSStatus(t *testing.T) {
+	state := tls.ConnectionState{Version: tls.VersionTLS13, CipherSuite: tls.TLS_AES_128_GCM_SHA256}
+	verifyChain := func([][]byte, [][]*x509.Certificate) error { return nil }
+
+	testCases := []struct {
+		name string
+		cfg  *tls.Config
+		want string
+	}{
+		{name: "verify-full", cfg: &tls.Config{ServerName: "db"}, want: "verified"},
+		{name: "verify-ca", cfg: &tls.Config{InsecureSkipVerify: true, VerifyPeerCertificate: verifyChain}, want: "verified, host name not checked"},
+		{name: "require", cfg: &tls.Config{InsecureSkipVerify: true}, want: "not verified"},
+		{name: "no config", want: "not verified"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tlsStatus(state, tc.cfg)
+			assert.Equal(t, "TLS 1.3", got.Version)
+			assert.Equal(t, "TLS_AES_128_GCM_SHA256", got.Cipher)
+			assert.Equal(t, tc.want, got.Verification)
+		})
+	}
+}
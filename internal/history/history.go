@@ -0,0 +1,196 @@
+// Package history stores the statements run in pgxcli along with when,
+// where and how they ran.
+//
+// The history file holds one JSON object per line. Files written by earlier
+// versions, which held one JSON string per query, are read as entries with
+// only the query known and rewritten in the current format.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/balaji01-4d/pgxcli/internal/config"
+)
+
+// maxLineSize bounds a single history entry, which holds a whole query.
+const maxLineSize = 16 * 1024 * 1024
+
+// Entry is one statement run from the prompt. Fields other than Query are
+// unknown for entries migrated from older history files.
+type Entry struct {
+	Query    string        `json:"query"`
+	Time     time.Time     `json:"time,omitzero"`
+	Host     string        `json:"host,omitempty"`
+	Database string        `json:"database,omitempty"`
+	User     string        `json:"user,omitempty"`
+	Duration time.Duration `json:"duration,omitempty"`
+	Rows     int64         `json:"rows,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Failed reports whether the statement ended in an error.
+func (e Entry) Failed() bool {
+	return e.Error != ""
+}
+
+// Path resolves the configured history file. An empty or "default" setting
+// means ~/.pgxcli_history.jsonl; an empty result means history is not kept.
+func Path(configured string) string {
+	if configured != "" && configured != config.Default {
+		return configured
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".pgxcli_history.jsonl")
+}
+
+// Load reads the history file, oldest entry first. A missing file is an
+// empty history. A file in the old format is migrated in place.
+func Load(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	migrate := false
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		entry, legacy := parseLine(line)
+		entries = append(entries, entry)
+		migrate = migrate || legacy
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	if migrate {
+		if err := Save(path, entries); err != nil {
+			return nil, fmt.Errorf("migrating %s: %w", path, err)
+		}
+	}
+	return entries, nil
+}
+
+// parseLine decodes one line of the history file, reporting whether it was
+// written in the old format: a JSON string, or failing that the raw query.
+func parseLine(line []byte) (Entry, bool) {
+	var entry Entry
+	if line[0] == '{' && json.Unmarshal(line, &entry) == nil {
+		return entry, false
+	}
+	var query string
+	if json.Unmarshal(line, &query) == nil {
+		return Entry{Query: query}, true
+	}
+	return Entry{Query: string(line)}, true
+}
+
+// Save replaces the history file with entries. The file is written under a
+// temporary name first so a failed write leaves the old history intact.
+func Save(path string, entries []Entry) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	for _, entry := range entries {
+		if err := writeEntry(w, entry); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Append adds entry to the end of the history file, creating it if needed.
+// Each entry is written as it completes, so sessions running side by side
+// do not overwrite each other's history.
+func Append(path string, entry Entry) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := writeEntry(f, entry); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func writeEntry(w io.Writer, entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// Filter returns the entries whose query contains pattern, ignoring case.
+// An empty pattern matches every entry.
+func Filter(entries []Entry, pattern string) []Entry {
+	if pattern == "" {
+		return entries
+	}
+	pattern = strings.ToLower(pattern)
+	var matched []Entry
+	for _, entry := range entries {
+		if strings.Contains(strings.ToLower(entry.Query), pattern) {
+			matched = append(matched, entry)
+		}
+	}
+	return matched
+}
+
+// Scoped returns the entries visible under scope from a connection to
+// database on host. Entries migrated from older history files carry no
+// connection, so they are only part of the global history.
+func Scoped(entries []Entry, scope config.HistoryScope, host, database string) []Entry {
+	if scope == config.HistoryScopeGlobal || scope == "" {
+		return entries
+	}
+	var scoped []Entry
+	for _, entry := range entries {
+		if entry.Host != host || entry.Time.IsZero() {
+			continue
+		}
+		if scope == config.HistoryScopeDatabase && entry.Database != database {
+			continue
+		}
+		scoped = append(scoped, entry)
+	}
+	return scoped
+}
@@ -0,0 +1,104 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/balaji01-4d/pgxcli/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_MissingFile(t *testing.T) {
+	entries, err := Load(filepath.Join(t.TempDir(), "history.jsonl"))
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestLoad_MigratesPlainStrings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte("\"select 1\"\n\n\"select\\n  2\"\nselect 3\n"), 0o600))
+
+	entries, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{{Query: "select 1"}, {Query: "select\n  2"}, {Query: "select 3"}}, entries)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"query\":\"select 1\"}\n{\"query\":\"select\\n  2\"}\n{\"query\":\"select 3\"}\n", string(data))
+
+	again, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, entries, again)
+}
+
+func TestAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	ran := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	first := Entry{
+		Query:    "select * from users",
+		Time:     ran,
+		Host:     "db.internal",
+		Database: "app",
+		User:     "alice",
+		Duration: 1500 * time.Millisecond,
+		Rows:     42,
+	}
+	second := Entry{Query: "drop table users", Time: ran.Add(time.Minute), Error: "permission denied"}
+
+	require.NoError(t, Append(path, first))
+	require.NoError(t, Append(path, second))
+
+	entries, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{first, second}, entries)
+	assert.False(t, entries[0].Failed())
+	assert.True(t, entries[1].Failed())
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestFilter(t *testing.T) {
+	entries := []Entry{{Query: "SELECT * FROM users"}, {Query: "select 1"}, {Query: "update users set x = 1"}}
+
+	assert.Equal(t, entries, Filter(entries, ""))
+	assert.Equal(t, []Entry{entries[0], entries[2]}, Filter(entries, "Users"))
+	assert.Empty(t, Filter(entries, "delete"))
+}
+
+func TestScoped(t *testing.T) {
+	ran := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	migrated := Entry{Query: "select 0"}
+	prodApp := Entry{Query: "select 1", Time: ran, Host: "prod", Database: "app"}
+	prodBilling := Entry{Query: "select 2", Time: ran, Host: "prod", Database: "billing"}
+	devApp := Entry{Query: "drop table users", Time: ran, Host: "localhost", Database: "app"}
+	entries := []Entry{migrated, prodApp, prodBilling, devApp}
+
+	testCases := []struct {
+		scope config.HistoryScope
+		want  []Entry
+	}{
+		{config.HistoryScopeGlobal, entries},
+		{config.HistoryScopeHost, []Entry{prodApp, prodBilling}},
+		{config.HistoryScopeDatabase, []Entry{prodApp}},
+	}
+	for _, tc := range testCases {
+		t.Run(string(tc.scope), func(t *testing.T) {
+			assert.Equal(t, tc.want, Scoped(entries, tc.scope, "prod", "app"))
+		})
+	}
+}
+
+func TestPath(t *testing.T) {
+	assert.Equal(t, "/tmp/h.jsonl", Path("/tmp/h.jsonl"))
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	want := filepath.Join(home, ".pgxcli_history.jsonl")
+	assert.Equal(t, want, Path(""))
+	assert.Equal(t, want, Path("default"))
+}
@@ -18,8 +18,9 @@ type Logger struct {
 
 // InitLogger creates a new structured logger with the specified debug level.
 // It writes to a file (creating parent directories if needed) and returns
-// a Logger wrapper for proper resource management.
-func InitLogger(debug bool, filename string) (*Logger, error) {
+// a Logger wrapper for proper resource management. When redact is set, it
+// masks secrets in every message and string attribute before it is written.
+func InitLogger(debug bool, filename string, redact func(string) string) (*Logger, error) {
 	if filename == "" || filename == "default" {
 		var err error
 		filename, err = getDefaultLogPath()
@@ -46,7 +47,10 @@ func InitLogger(debug bool, filename string) (*Logger, error) {
 		return nil, fmt.Errorf("failed to open log file: %w", err)
 	}
 
-	handler := slog.NewTextHandler(file, opts)
+	var handler slog.Handler = slog.NewTextHandler(file, opts)
+	if redact != nil {
+		handler = redactHandler{Handler: handler, redact: redact}
+	}
 	return &Logger{
 		Logger: slog.New(handler),
 		file:   file,
@@ -1,14 +1,17 @@
 package logger
 
 import (
+	"errors"
 	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestInitLogger_CreatesLogFileWithOwnerOnlyPermissions(t *testing.T) {
@@ -18,7 +21,7 @@ func TestInitLogger_CreatesLogFileWithOwnerOnlyPermissions(t *testing.T) {
 
 	logPath := filepath.Join(t.TempDir(), "app.log")
 
-	logger, err := InitLogger(false, logPath)
+	logger, err := InitLogger(false, logPath, nil)
 	assert.NoError(t, err)
 	t.Cleanup(func() {
 		_ = logger.Close()
@@ -34,11 +37,37 @@ func TestInitLogger_CreatesLogFileWithOwnerOnlyPermissions(t *testing.T) {
 func TestInitLogger_ReturnsErrorWhenPathIsDirectory(t *testing.T) {
 	dirPath := t.TempDir()
 
-	logger, err := InitLogger(false, dirPath)
+	logger, err := InitLogger(false, dirPath, nil)
 	assert.Error(t, err)
 	assert.Nil(t, logger)
 }
 
+func TestInitLogger_RedactsSecrets(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	redact := func(s string) string {
+		return strings.ReplaceAll(s, "hunter2", "***")
+	}
+
+	logger, err := InitLogger(true, logPath, redact)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = logger.Close()
+	})
+
+	logger.With("conn", "password=hunter2").Debug("Executing query",
+		"sql", "ALTER ROLE app PASSWORD 'hunter2'",
+		"error", errors.New("bad password hunter2"),
+		slog.Group("req", "query", "hunter2"),
+		"rows", 3,
+	)
+
+	data, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "hunter2")
+	assert.Contains(t, string(data), `sql="ALTER ROLE app PASSWORD '***'"`)
+	assert.Contains(t, string(data), "rows=3")
+}
+
 func TestNopLogger(t *testing.T) {
 	logger := NopLogger()
 	assert.Nil(t, logger.file)
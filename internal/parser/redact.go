@@ -0,0 +1,205 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RedactMask replaces each secret removed by a Redactor.
+const RedactMask = "********"
+
+// conninfoPassword matches the password of a libpq connection string, such as
+// the one given to CREATE SUBSCRIPTION ... CONNECTION or dblink.
+var conninfoPassword = regexp.MustCompile(`(?i)(\bpassword\s*=\s*)(\S+)`)
+
+// Redactor masks secrets in SQL before it is written to the history file or
+// the log. Password literals are always masked; configured patterns are
+// masked in addition. A nil Redactor masks password literals only.
+type Redactor struct {
+	patterns []*regexp.Regexp
+}
+
+// NewRedactor compiles the configured patterns. When a pattern has capture
+// groups only the groups are masked, otherwise the whole match is.
+func NewRedactor(patterns []string) (*Redactor, error) {
+	r := &Redactor{}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", pattern, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// Redact returns sql with its secrets masked.
+func (r *Redactor) Redact(sql string) string {
+	sql = redactPasswords(sql)
+	if r == nil {
+		return sql
+	}
+	for _, re := range r.patterns {
+		sql = maskMatches(re, sql)
+	}
+	return sql
+}
+
+// maskMatches masks the capture groups of each match of re in s, or the
+// whole match when re has no groups.
+func maskMatches(re *regexp.Regexp, s string) string {
+	if re.NumSubexp() == 0 {
+		return re.ReplaceAllLiteralString(s, RedactMask)
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, match := range re.FindAllStringSubmatchIndex(s, -1) {
+		for g := 1; g <= re.NumSubexp(); g++ {
+			start, end := match[2*g], match[2*g+1]
+			if start < last {
+				// unmatched, or nested in a group already masked
+				continue
+			}
+			sb.WriteString(s[last:start])
+			sb.WriteString(RedactMask)
+			last = end
+		}
+	}
+	sb.WriteString(s[last:])
+	return sb.String()
+}
+
+// redactPasswords masks string literals that follow the word PASSWORD, as in
+// ALTER ROLE ... PASSWORD '...' or OPTIONS (password '...'), and passwords in
+// connection strings. Comments, quoted identifiers and the rest of the
+// statement are left as they are.
+func redactPasswords(sql string) string {
+	r := &redactScanner{sql: sql}
+	for i := 0; i < len(sql); {
+		i = r.next(i)
+	}
+	if r.last == 0 {
+		return sql
+	}
+	r.sb.WriteString(sql[r.last:])
+	return r.sb.String()
+}
+
+// redactScanner walks the tokens of a statement, copying it to sb with the
+// secret literals masked.
+type redactScanner struct {
+	sql string
+	sb  strings.Builder
+	// last is where the text not yet copied to sb starts.
+	last int
+	// prev is the last word seen, lower-cased; "=" between it and a literal
+	// is allowed.
+	prev string
+	// escape is set when the next literal is an E'' escape string.
+	escape bool
+}
+
+// next scans the token starting at i and returns where the next one starts.
+func (r *redactScanner) next(i int) int {
+	sql := r.sql
+	c := sql[i]
+	switch {
+	case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '=':
+		return i + 1
+	case strings.HasPrefix(sql[i:], "--"):
+		end := strings.IndexByte(sql[i:], '\n')
+		if end == -1 {
+			return len(sql)
+		}
+		return i + end + 1
+	case strings.HasPrefix(sql[i:], "/*"):
+		return len(sql) - len(skipBlockComment(sql[i:]))
+	case c == '\'':
+		return r.quotedLiteral(i)
+	case c == '"':
+		end := skipQuoted(sql, i, false)
+		r.prev = strings.ToLower(sql[i+1 : quotedBodyEnd(sql, i, end)])
+		return end
+	case c == '$':
+		return r.dollarLiteral(i)
+	case isIdentByte(c):
+		return r.word(i)
+	default:
+		r.prev = ""
+		return i + 1
+	}
+}
+
+func (r *redactScanner) quotedLiteral(i int) int {
+	end := skipQuoted(r.sql, i, r.escape)
+	for end < len(r.sql) && r.sql[end] == '\'' {
+		// a doubled quote inside the literal
+		end = skipQuoted(r.sql, end, r.escape)
+	}
+	r.mask(i+1, quotedBodyEnd(r.sql, i, end))
+	r.escape = false
+	return end
+}
+
+func (r *redactScanner) dollarLiteral(i int) int {
+	tag, ok := readDollarTag(r.sql[i+1:])
+	if !ok {
+		r.prev = ""
+		return i + 1
+	}
+	delim := "$" + tag + "$"
+	start := i + len(delim)
+	end := strings.Index(r.sql[start:], delim)
+	if end == -1 {
+		r.mask(start, len(r.sql))
+		return len(r.sql)
+	}
+	r.mask(start, start+end)
+	return start + end + len(delim)
+}
+
+func (r *redactScanner) word(i int) int {
+	j := i
+	for j < len(r.sql) && (isIdentByte(r.sql[j]) || r.sql[j] == '$') {
+		j++
+	}
+	word := r.sql[i:j]
+	if (word == "E" || word == "e") && j < len(r.sql) && r.sql[j] == '\'' {
+		// the E of an escape string, not a word
+		r.escape = true
+	} else {
+		r.prev = strings.ToLower(word)
+	}
+	return j
+}
+
+// mask masks the secrets of the literal body sql[start:end].
+func (r *redactScanner) mask(start, end int) {
+	body := r.sql[start:end]
+	if masked := maskLiteral(body, r.prev == "password"); masked != body {
+		r.sb.WriteString(r.sql[r.last:start])
+		r.sb.WriteString(masked)
+		r.last = end
+	}
+	r.prev = ""
+}
+
+// quotedBodyEnd returns where the body of the quoted text from start to end
+// stops: before the closing quote, or at end if the quote is unterminated.
+func quotedBodyEnd(sql string, start, end int) int {
+	if end-1 > start && sql[end-1] == sql[start] {
+		return end - 1
+	}
+	return end
+}
+
+// maskLiteral returns the body of a string literal with its secrets masked:
+// all of it when it is a password, or the password of a connection string.
+func maskLiteral(body string, secret bool) string {
+	if secret {
+		return RedactMask
+	}
+	return conninfoPassword.ReplaceAllString(body, "${1}"+RedactMask)
+}
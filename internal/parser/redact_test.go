@@ -0,0 +1,64 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/balaji01-4d/pgxcli/internal/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedact_Passwords(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{"NoSecret", "SELECT 'password' FROM users", "SELECT 'password' FROM users"},
+		{"AlterRole", "ALTER ROLE app PASSWORD 'hunter2'", "ALTER ROLE app PASSWORD '********'"},
+		{"Encrypted", "create user app with encrypted password 'it''s secret' login", "create user app with encrypted password '********' login"},
+		{"EscapeString", `ALTER ROLE app PASSWORD E'a\'b'`, `ALTER ROLE app PASSWORD E'********'`},
+		{"DollarQuoted", "ALTER ROLE app PASSWORD $pw$hunter2$pw$;", "ALTER ROLE app PASSWORD $pw$********$pw$;"},
+		{"Unterminated", "ALTER ROLE app PASSWORD 'hunter2", "ALTER ROLE app PASSWORD '********"},
+		{"NullPassword", "ALTER ROLE app PASSWORD NULL", "ALTER ROLE app PASSWORD NULL"},
+		{
+			"UserMapping",
+			"CREATE USER MAPPING FOR app SERVER s OPTIONS (user 'app', password 'hunter2')",
+			"CREATE USER MAPPING FOR app SERVER s OPTIONS (user 'app', password '********')",
+		},
+		{
+			"QuotedOption",
+			`ALTER USER MAPPING FOR app SERVER s OPTIONS (SET "password" 'hunter2')`,
+			`ALTER USER MAPPING FOR app SERVER s OPTIONS (SET "password" '********')`,
+		},
+		{"Equals", "SET app.password = 'hunter2'", "SET app.password = '********'"},
+		{
+			"ConnectionString",
+			"CREATE SUBSCRIPTION s CONNECTION 'host=db user=rep password=hunter2 dbname=app' PUBLICATION p",
+			"CREATE SUBSCRIPTION s CONNECTION 'host=db user=rep password=******** dbname=app' PUBLICATION p",
+		},
+		{"Comment", "-- PASSWORD 'x'\nSELECT 1", "-- PASSWORD 'x'\nSELECT 1"},
+		{"NotAfterOtherWords", "ALTER ROLE app PASSWORD 'a' VALID UNTIL '2030-01-01'", "ALTER ROLE app PASSWORD '********' VALID UNTIL '2030-01-01'"},
+	}
+
+	var r *parser.Redactor
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, r.Redact(tt.sql))
+		})
+	}
+}
+
+func TestRedact_Patterns(t *testing.T) {
+	r, err := parser.NewRedactor([]string{`sk_live_\w+`, `(?i)api_key\s*=\s*'([^']*)'`})
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		"INSERT INTO keys VALUES ('********'); SELECT api_key = '********'",
+		r.Redact("INSERT INTO keys VALUES ('sk_live_abc123'); SELECT api_key = 'k-42'"),
+	)
+	assert.Equal(t, "ALTER ROLE app PASSWORD '********'", r.Redact("ALTER ROLE app PASSWORD 'x'"))
+
+	_, err = parser.NewRedactor([]string{"("})
+	assert.ErrorContains(t, err, `invalid redact pattern "("`)
+}
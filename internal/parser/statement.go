@@ -0,0 +1,288 @@
+package parser
+
+import (
+	"strings"
+	"unicode"
+)
+
+// leadingKeywords returns up to n upper-cased words at the start of sql,
+// skipping leading whitespace and comments.
+func leadingKeywords(sql string, n int) []string {
+	words := make([]string, 0, n)
+	rest := sql
+	for len(words) < n {
+		rest = trimLeadingComments(rest)
+		end := strings.IndexFunc(rest, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+		})
+		if end == -1 {
+			end = len(rest)
+		}
+		if end == 0 {
+			return words
+		}
+		words = append(words, strings.ToUpper(rest[:end]))
+		rest = rest[end:]
+	}
+	return words
+}
+
+// trimLeadingComments strips whitespace and comments from the start of sql.
+func trimLeadingComments(sql string) string {
+	for {
+		sql = strings.TrimLeftFunc(sql, unicode.IsSpace)
+		switch {
+		case strings.HasPrefix(sql, "--"):
+			idx := strings.IndexByte(sql, '\n')
+			if idx == -1 {
+				return ""
+			}
+			sql = sql[idx+1:]
+		case strings.HasPrefix(sql, "/*"):
+			sql = skipBlockComment(sql)
+		default:
+			return sql
+		}
+	}
+}
+
+// skipBlockComment returns s with its leading, possibly nested, /* */ comment removed.
+func skipBlockComment(s string) string {
+	depth := 0
+	for i := 0; i < len(s)-1; i++ {
+		switch s[i : i+2] {
+		case "/*":
+			depth++
+			i++
+		case "*/":
+			depth--
+			i++
+			if depth == 0 {
+				return s[i+1:]
+			}
+		}
+	}
+	return ""
+}
+
+// CommandNoBegin reports whether sql must not be preceded by an implicit
+// BEGIN when autocommit is off. It mirrors psql's command_no_begin: transaction
+// control statements and commands that cannot run inside a transaction block.
+func CommandNoBegin(sql string) bool {
+	words := leadingKeywords(sql, 4)
+	if len(words) == 0 {
+		return true
+	}
+
+	second := func() string {
+		if len(words) > 1 {
+			return words[1]
+		}
+		return ""
+	}
+
+	switch words[0] {
+	case "ABORT", "BEGIN", "START", "COMMIT", "END", "ROLLBACK", "VACUUM", "CLUSTER":
+		return true
+	case "PREPARE":
+		return second() == "TRANSACTION"
+	case "CREATE", "DROP":
+		switch second() {
+		case "DATABASE", "TABLESPACE":
+			return true
+		case "INDEX", "UNIQUE":
+			return containsWord(words[2:], "CONCURRENTLY")
+		}
+	case "REINDEX":
+		switch second() {
+		case "DATABASE", "SYSTEM":
+			return true
+		}
+		return containsWord(words[1:], "CONCURRENTLY")
+	case "ALTER":
+		return second() == "SYSTEM"
+	case "DISCARD":
+		return second() == "ALL"
+	}
+	return false
+}
+
+// EndsTransaction reports whether sql ends the current transaction block,
+// committing or rolling it back. ROLLBACK TO SAVEPOINT and the CHAIN forms
+// leave a transaction open.
+func EndsTransaction(sql string) bool {
+	words := leadingKeywords(sql, 4)
+	if len(words) == 0 || containsWord(words, "CHAIN") {
+		return false
+	}
+	switch words[0] {
+	case "COMMIT", "END", "ABORT":
+		return len(words) == 1 || words[1] != "PREPARED"
+	case "ROLLBACK":
+		return len(words) == 1 || (words[1] != "TO" && words[1] != "PREPARED")
+	case "PREPARE":
+		return len(words) > 1 && words[1] == "TRANSACTION"
+	}
+	return false
+}
+
+func containsWord(words []string, word string) bool {
+	for _, w := range words {
+		if w == word {
+			return true
+		}
+	}
+	return false
+}
+
+// SettingChange describes a statement that changes a session-level parameter.
+type SettingChange struct {
+	// Name is the lower-cased parameter name, empty for RESET ALL.
+	Name string
+	// Reset is true for RESET statements.
+	Reset bool
+}
+
+// ParseSettingChange reports whether sql is a SET or RESET statement whose
+// effect outlives the current transaction. SET LOCAL, SET TRANSACTION,
+// SET CONSTRAINTS and SET SESSION CHARACTERISTICS are not session settings.
+func ParseSettingChange(sql string) (SettingChange, bool) {
+	fields := strings.Fields(strings.TrimRight(trimLeadingComments(sql), "; \t\r\n"))
+	if len(fields) < 2 {
+		return SettingChange{}, false
+	}
+
+	verb := strings.ToUpper(fields[0])
+	if verb != "SET" && verb != "RESET" {
+		return SettingChange{}, false
+	}
+
+	rest := fields[1:]
+	if verb == "SET" {
+		switch strings.ToUpper(rest[0]) {
+		case "LOCAL", "TRANSACTION", "CONSTRAINTS":
+			return SettingChange{}, false
+		case "SESSION":
+			rest = rest[1:]
+			if len(rest) == 0 || strings.EqualFold(rest[0], "CHARACTERISTICS") {
+				return SettingChange{}, false
+			}
+		}
+	}
+
+	name, _, _ := strings.Cut(rest[0], "=")
+	name = strings.ToLower(name)
+	if verb == "RESET" && name == "all" {
+		return SettingChange{Reset: true}, true
+	}
+	return SettingChange{Name: name, Reset: verb == "RESET"}, true
+}
+
+// Placeholders returns the highest $n parameter number used in sql, or zero
+// if it has none. Placeholders inside string literals, quoted identifiers,
+// dollar-quoted bodies and comments are ignored, as are those of a PREPARE
+// statement, which belong to the statement being prepared.
+func Placeholders(sql string) int {
+	if words := leadingKeywords(sql, 1); len(words) == 1 && words[0] == "PREPARE" {
+		return 0
+	}
+
+	highest := 0
+	for i := 0; i < len(sql); {
+		switch {
+		case sql[i] == '\'' || sql[i] == '"':
+			i = skipQuoted(sql, i, i > 0 && (sql[i-1] == 'E' || sql[i-1] == 'e'))
+		case strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end == -1 {
+				return highest
+			}
+			i += end + 1
+		case strings.HasPrefix(sql[i:], "/*"):
+			i = len(sql) - len(skipBlockComment(sql[i:]))
+		case sql[i] == '$' && (i == 0 || !isIdentByte(sql[i-1])):
+			var n int
+			n, i = scanDollar(sql, i)
+			highest = max(highest, n)
+		default:
+			i++
+		}
+	}
+	return highest
+}
+
+// SplitMetaCommand splits sql at the first backslash outside string
+// literals, quoted identifiers, dollar-quoted bodies and comments, as psql
+// does for a meta-command ending a query, e.g. "SELECT $1 \parse q". Both
+// parts are trimmed; meta is empty when sql has no such backslash.
+func SplitMetaCommand(sql string) (query, meta string) {
+	for i := 0; i < len(sql); {
+		switch {
+		case sql[i] == '\\':
+			return strings.TrimSpace(sql[:i]), strings.TrimSpace(sql[i:])
+		case sql[i] == '\'' || sql[i] == '"':
+			i = skipQuoted(sql, i, i > 0 && (sql[i-1] == 'E' || sql[i-1] == 'e'))
+		case strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end == -1 {
+				return strings.TrimSpace(sql), ""
+			}
+			i += end + 1
+		case strings.HasPrefix(sql[i:], "/*"):
+			i = len(sql) - len(skipBlockComment(sql[i:]))
+		case sql[i] == '$' && (i == 0 || !isIdentByte(sql[i-1])):
+			_, i = scanDollar(sql, i)
+		default:
+			i++
+		}
+	}
+	return strings.TrimSpace(sql), ""
+}
+
+// skipQuoted returns the index just past the quoted literal or identifier
+// starting at sql[start]. Backslash escapes apply to E-prefixed strings.
+func skipQuoted(sql string, start int, backslashEscapes bool) int {
+	quote := sql[start]
+	for i := start + 1; i < len(sql); i++ {
+		switch {
+		case backslashEscapes && sql[i] == '\\':
+			i++
+		case sql[i] == quote:
+			return i + 1
+		}
+	}
+	return len(sql)
+}
+
+// scanDollar handles a '$' at sql[start]: a $n placeholder returns n, and a
+// dollar-quoted body is skipped. It returns the index to continue from.
+func scanDollar(sql string, start int) (int, int) {
+	i := start + 1
+	for i < len(sql) && sql[i] >= '0' && sql[i] <= '9' {
+		i++
+	}
+	if i > start+1 {
+		n := 0
+		for _, c := range sql[start+1 : i] {
+			n = n*10 + int(c-'0')
+		}
+		return n, i
+	}
+
+	for i < len(sql) && isIdentByte(sql[i]) {
+		i++
+	}
+	if i >= len(sql) || sql[i] != '$' {
+		return 0, start + 1
+	}
+	tag := sql[start : i+1]
+	end := strings.Index(sql[i+1:], tag)
+	if end == -1 {
+		return 0, len(sql)
+	}
+	return 0, i + 1 + end + len(tag)
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
@@ -0,0 +1,165 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/balaji01-4d/pgxcli/internal/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandNoBegin(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want bool
+	}{
+		{"Empty", "", true},
+		{"Select", "SELECT 1;", false},
+		{"Insert", "insert into t values (1)", false},
+		{"Begin", "BEGIN;", true},
+		{"StartTransaction", "start transaction isolation level serializable", true},
+		{"CommitLowercase", "commit", true},
+		{"RollbackPrepared", "ROLLBACK PREPARED 'tx1'", true},
+		{"End", "END", true},
+		{"Abort", "abort;", true},
+		{"PrepareTransaction", "PREPARE TRANSACTION 'tx1'", true},
+		{"PrepareStatement", "PREPARE q AS SELECT 1", false},
+		{"Vacuum", "VACUUM ANALYZE t", true},
+		{"CreateDatabase", "CREATE DATABASE app", true},
+		{"DropTablespace", "drop tablespace ts", true},
+		{"CreateIndex", "CREATE INDEX idx ON t (a)", false},
+		{"CreateIndexConcurrently", "CREATE INDEX CONCURRENTLY idx ON t (a)", true},
+		{"CreateUniqueIndexConcurrently", "CREATE UNIQUE INDEX CONCURRENTLY idx ON t (a)", true},
+		{"DropIndexConcurrently", "DROP INDEX CONCURRENTLY idx", true},
+		{"ReindexDatabase", "REINDEX DATABASE app", true},
+		{"ReindexTableConcurrently", "REINDEX TABLE CONCURRENTLY t", true},
+		{"ReindexTable", "REINDEX TABLE t", false},
+		{"AlterSystem", "ALTER SYSTEM SET work_mem = '64MB'", true},
+		{"AlterTable", "ALTER TABLE t ADD COLUMN b int", false},
+		{"DiscardAll", "DISCARD ALL", true},
+		{"DiscardPlans", "DISCARD PLANS", false},
+		{"LeadingLineComment", "-- note\nBEGIN", true},
+		{"LeadingBlockComment", "/* a /* nested */ comment */ VACUUM", true},
+		{"OnlyComment", "-- nothing here", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parser.CommandNoBegin(tt.sql))
+		})
+	}
+}
+
+func TestParseSettingChange(t *testing.T) {
+	tests := []struct {
+		name   string
+		sql    string
+		want   parser.SettingChange
+		wantOk bool
+	}{
+		{"Set", "SET search_path TO app, public;", parser.SettingChange{Name: "search_path"}, true},
+		{"SetEquals", "set work_mem='64MB'", parser.SettingChange{Name: "work_mem"}, true},
+		{"SetSession", "SET SESSION statement_timeout = 0", parser.SettingChange{Name: "statement_timeout"}, true},
+		{"SetCustom", "SET app.tenant = 'a'", parser.SettingChange{Name: "app.tenant"}, true},
+		{"SetRole", "SET ROLE reporting", parser.SettingChange{Name: "role"}, true},
+		{"Reset", "RESET work_mem", parser.SettingChange{Name: "work_mem", Reset: true}, true},
+		{"ResetAll", "RESET ALL;", parser.SettingChange{Reset: true}, true},
+		{"SetLocal", "SET LOCAL work_mem = '1MB'", parser.SettingChange{}, false},
+		{"SetTransaction", "SET TRANSACTION READ ONLY", parser.SettingChange{}, false},
+		{"SetConstraints", "SET CONSTRAINTS ALL DEFERRED", parser.SettingChange{}, false},
+		{"SetSessionCharacteristics", "SET SESSION CHARACTERISTICS AS TRANSACTION READ ONLY", parser.SettingChange{}, false},
+		{"Select", "SELECT set_config('a', 'b', false)", parser.SettingChange{}, false},
+		{"BareSet", "SET", parser.SettingChange{}, false},
+		{"LeadingComment", "-- tune\nSET jit = off", parser.SettingChange{Name: "jit"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parser.ParseSettingChange(tt.sql)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPlaceholders(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want int
+	}{
+		{"None", "SELECT 1", 0},
+		{"Single", "SELECT * FROM users WHERE id = $1", 1},
+		{"Highest", "SELECT $2, $1, $10::int", 10},
+		{"InString", "SELECT '$1', $2", 2},
+		{"DoubledQuote", "SELECT 'it''s $3', $1", 1},
+		{"EscapeString", `SELECT E'\'$3', $1`, 1},
+		{"QuotedIdentifier", `SELECT "$5" FROM t WHERE a = $1`, 1},
+		{"LineComment", "SELECT $1 -- and $4\n", 1},
+		{"BlockComment", "SELECT /* $4 /* $5 */ */ $2", 2},
+		{"DollarQuoted", "DO $$ BEGIN PERFORM $1; END $$", 0},
+		{"TaggedDollarQuote", "SELECT $fn$ $1 $fn$, $3", 3},
+		{"IdentifierWithDollar", "SELECT a$1 FROM t", 0},
+		{"Prepare", "PREPARE q(int) AS SELECT $1", 0},
+		{"UnterminatedString", "SELECT '$1", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parser.Placeholders(tt.sql))
+		})
+	}
+}
+
+func TestSplitMetaCommand(t *testing.T) {
+	tests := []struct {
+		name      string
+		sql       string
+		wantQuery string
+		wantMeta  string
+	}{
+		{"None", "SELECT 1", "SELECT 1", ""},
+		{"Trailing", "SELECT * FROM users WHERE id = $1 \\parse by_id", "SELECT * FROM users WHERE id = $1", "\\parse by_id"},
+		{"MetaOnly", "\\dt", "", "\\dt"},
+		{"InString", `SELECT 'a\b' \parse q`, `SELECT 'a\b'`, `\parse q`},
+		{"EscapeString", `SELECT E'\'\\' \parse q`, `SELECT E'\'\\'`, `\parse q`},
+		{"LineComment", "SELECT 1 -- \\x\n\\parse q", "SELECT 1 -- \\x", "\\parse q"},
+		{"DollarQuoted", `SELECT $$\n$$ \parse q`, `SELECT $$\n$$`, `\parse q`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, meta := parser.SplitMetaCommand(tt.sql)
+			assert.Equal(t, tt.wantQuery, query)
+			assert.Equal(t, tt.wantMeta, meta)
+		})
+	}
+}
+
+func TestEndsTransaction(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want bool
+	}{
+		{"COMMIT", true},
+		{"commit work", true},
+		{"END", true},
+		{"ROLLBACK", true},
+		{"abort transaction", true},
+		{"PREPARE TRANSACTION 'tx1'", true},
+		{"ROLLBACK TO SAVEPOINT a", false},
+		{"ROLLBACK TO a", false},
+		{"COMMIT AND CHAIN", false},
+		{"COMMIT PREPARED 'tx1'", false},
+		{"ROLLBACK PREPARED 'tx1'", false},
+		{"PREPARE q AS SELECT 1", false},
+		{"SELECT 1", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			assert.Equal(t, tt.want, parser.EndsTransaction(tt.sql))
+		})
+	}
+}
@@ -0,0 +1,68 @@
+// Package secrets finds connection passwords that are kept out of the
+// command line and the environment: printed by a command, such as a
+// password manager, or read from a password store encrypted with a master
+// passphrase.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/google/shlex"
+)
+
+// Target identifies the connection a password is for.
+type Target struct {
+	Host     string
+	Port     uint16
+	Database string
+	User     string
+}
+
+func (t Target) port() string {
+	if t.Port == 0 {
+		return ""
+	}
+	return strconv.FormatUint(uint64(t.Port), 10)
+}
+
+// FromCommand runs command and returns the first line it prints. The
+// command is split into words like a shell would, but is not run through
+// one. It keeps the terminal, so a password manager can ask for its own
+// passphrase, and is given the connection in PGXCLI_HOST, PGXCLI_PORT,
+// PGXCLI_DATABASE and PGXCLI_USER.
+func FromCommand(ctx context.Context, command string, target Target) (string, error) {
+	args, err := shlex.Split(command)
+	if err != nil {
+		return "", fmt.Errorf("password_command: %w", err)
+	}
+	if len(args) == 0 {
+		return "", errors.New("password_command is empty")
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Env = append(os.Environ(),
+		"PGXCLI_HOST="+target.Host,
+		"PGXCLI_PORT="+target.port(),
+		"PGXCLI_DATABASE="+target.Database,
+		"PGXCLI_USER="+target.User,
+	)
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("password_command %s: %w", args[0], err)
+	}
+
+	password, _, _ := strings.Cut(string(out), "\n")
+	password = strings.TrimSuffix(password, "\r")
+	if password == "" {
+		return "", fmt.Errorf("password_command %s printed no password", args[0])
+	}
+	return password, nil
+}
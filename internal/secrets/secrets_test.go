@@ -0,0 +1,109 @@
+package secrets
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var prod = Target{Host: "db.example.com", Port: 5432, Database: "app", User: "readonly"}
+
+func TestFromCommand(t *testing.T) {
+	testCases := []struct {
+		name    string
+		command string
+		want    string
+		wantErr string
+	}{
+		{name: "first line", command: `sh -c 'printf "s3cret\nurl: db.example.com\n"'`, want: "s3cret"},
+		{name: "crlf", command: `sh -c 'printf "s3cret\r\n"'`, want: "s3cret"},
+		{name: "no newline", command: "printf s3cret", want: "s3cret"},
+		{name: "spaces kept", command: `printf "  two words "`, want: "  two words "},
+		{name: "connection in env", command: `sh -c 'echo "$PGXCLI_USER@$PGXCLI_HOST:$PGXCLI_PORT/$PGXCLI_DATABASE"'`, want: "readonly@db.example.com:5432/app"},
+		{name: "not run through a shell", command: "echo $HOME", want: "$HOME"},
+		{name: "fails", command: "false", wantErr: "password_command false: exit status 1"},
+		{name: "prints nothing", command: "true", wantErr: "printed no password"},
+		{name: "empty", command: "  ", wantErr: "password_command is empty"},
+		{name: "unbalanced quote", command: `pass show "db/prod`, wantErr: "password_command:"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := FromCommand(context.Background(), tc.command, prod)
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestStore_SaveAndOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pgxcli", "passwords.enc")
+
+	store, err := OpenStore(path, "correct horse")
+	require.NoError(t, err)
+	_, ok := store.Lookup(prod)
+	assert.False(t, ok)
+
+	store.Set(prod, "first")
+	store.Set(prod, "s3cret")
+	require.NoError(t, store.Save())
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "s3cret")
+	assert.NotContains(t, string(raw), "db.example.com")
+
+	reopened, err := OpenStore(path, "correct horse")
+	require.NoError(t, err)
+	password, ok := reopened.Lookup(prod)
+	assert.True(t, ok)
+	assert.Equal(t, "s3cret", password)
+	assert.Len(t, reopened.entries, 1)
+
+	_, err = OpenStore(path, "wrong horse")
+	assert.ErrorIs(t, err, ErrWrongPassphrase)
+}
+
+func TestStore_Lookup(t *testing.T) {
+	store := &Store{entries: []Entry{
+		{Host: "db.example.com", Port: "5432", Database: "app", User: "readonly", Password: "exact"},
+		{Host: "db.example.com", Port: "*", Database: "*", User: "admin", Password: "admin"},
+		{Host: "*", Port: "*", Database: "*", User: "*", Password: "fallback"},
+	}}
+
+	testCases := []struct {
+		name   string
+		target Target
+		want   string
+	}{
+		{name: "exact", target: prod, want: "exact"},
+		{name: "wildcards", target: Target{Host: "db.example.com", Port: 6432, Database: "reports", User: "admin"}, want: "admin"},
+		{name: "first match wins", target: Target{Host: "localhost", Port: 5432, Database: "app", User: "readonly"}, want: "fallback"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := store.Lookup(tc.target)
+			assert.True(t, ok)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestStorePath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg")
+	assert.Equal(t, filepath.Join("/tmp/xdg", "pgxcli", "passwords.enc"), StorePath("default"))
+	assert.Equal(t, "/secure/passwords.enc", StorePath("/secure/passwords.enc"))
+	assert.Empty(t, StorePath(""))
+}
@@ -0,0 +1,205 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/balaji01-4d/pgxcli/internal/config"
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	storeVersion  = 1
+	storeFilename = "passwords.enc"
+	saltSize      = 16
+	keySize       = 32
+
+	// argon2id parameters, as recommended by RFC 9106 for memory-constrained
+	// systems.
+	kdfTime    = 3
+	kdfMemory  = 64 * 1024
+	kdfThreads = 4
+)
+
+// ErrWrongPassphrase is returned when a password store cannot be decrypted,
+// which is nearly always a mistyped master passphrase.
+var ErrWrongPassphrase = errors.New("wrong master passphrase, or the password store is damaged")
+
+// Entry is the password of the connections it matches. Like the lines of a
+// .pgpass file, a field of "*" matches any value.
+type Entry struct {
+	Host     string `json:"host"`
+	Port     string `json:"port"`
+	Database string `json:"database"`
+	User     string `json:"user"`
+	Password string `json:"password"`
+}
+
+func (e Entry) matches(t Target) bool {
+	match := func(field, value string) bool { return field == "*" || field == value }
+	return match(e.Host, t.Host) && match(e.Port, t.port()) &&
+		match(e.Database, t.Database) && match(e.User, t.User)
+}
+
+// storeFile is the JSON form of a password store on disk. Data holds the
+// entries, sealed with AES-256-GCM under a key derived from the master
+// passphrase and Salt with argon2id.
+type storeFile struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// Store is an unlocked password store.
+type Store struct {
+	path       string
+	passphrase string
+	entries    []Entry
+}
+
+// StorePath resolves the configured password store. A "default" setting
+// means passwords.enc next to the user config file; an empty setting, or
+// an empty result, means no password store is used.
+func StorePath(configured string) string {
+	if configured != config.Default {
+		return configured
+	}
+	userPath, err := config.UserConfigPath()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(userPath), storeFilename)
+}
+
+// OpenStore unlocks the password store at path with passphrase. A missing
+// file is an empty store, which is created on Save.
+func OpenStore(path, passphrase string) (*Store, error) {
+	store := &Store{path: path, passphrase: passphrase}
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file storeFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("read password store %s: %w", path, err)
+	}
+	if file.Version != storeVersion {
+		return nil, fmt.Errorf("password store %s has unsupported version %d", path, file.Version)
+	}
+	aead, err := newAEAD(passphrase, file.Salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(file.Nonce) != aead.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+	data, err := aead.Open(nil, file.Nonce, file.Data, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	if err := json.Unmarshal(data, &store.entries); err != nil {
+		return nil, fmt.Errorf("read password store %s: %w", path, err)
+	}
+	return store, nil
+}
+
+// Lookup returns the password of the first entry matching t.
+func (s *Store) Lookup(t Target) (string, bool) {
+	for _, entry := range s.entries {
+		if entry.matches(t) {
+			return entry.Password, true
+		}
+	}
+	return "", false
+}
+
+// Set stores password for exactly the connection t, replacing the password
+// it had.
+func (s *Store) Set(t Target, password string) {
+	entry := Entry{Host: t.Host, Port: t.port(), Database: t.Database, User: t.User, Password: password}
+	for i, existing := range s.entries {
+		if existing.Host == entry.Host && existing.Port == entry.Port &&
+			existing.Database == entry.Database && existing.User == entry.User {
+			s.entries[i] = entry
+			return
+		}
+	}
+	s.entries = append(s.entries, entry)
+}
+
+// Save encrypts the store with a fresh salt and nonce and writes it,
+// readable by the user only.
+func (s *Store) Save() error {
+	data, err := json.Marshal(s.entries)
+	if err != nil {
+		return err
+	}
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	aead, err := newAEAD(s.passphrase, salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	raw, err := json.Marshal(storeFile{
+		Version: storeVersion,
+		Salt:    salt,
+		Nonce:   nonce,
+		Data:    aead.Seal(nil, nonce, data, nil),
+	})
+	if err != nil {
+		return err
+	}
+	return writeFile(s.path, raw)
+}
+
+func newAEAD(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key := argon2.IDKey([]byte(passphrase), salt, kdfTime, kdfMemory, kdfThreads, keySize)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// writeFile replaces path with data through a temporary file, so a failed
+// write does not lose the passwords already stored.
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,256 @@
+// Package sshtunnel opens SSH connections to a bastion host and dials the
+// database through them, for servers that are only reachable from a jump host.
+package sshtunnel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	defaultPort = "22"
+	dialTimeout = 10 * time.Second
+)
+
+// defaultIdentities are the keys in ~/.ssh tried when no identity file is
+// given, like ssh does.
+var defaultIdentities = []string{"id_ed25519", "id_ecdsa", "id_rsa"}
+
+// Config describes the bastion host to tunnel through.
+type Config struct {
+	// Target is the bastion as user@host[:port]. The user defaults to the OS
+	// user and the port to 22.
+	Target string
+	// IdentityFile is a private key to authenticate with, tried after the
+	// keys of the SSH agent. When empty, the default keys in ~/.ssh are tried.
+	IdentityFile string
+	// KnownHostsFile verifies the host key of the bastion. It defaults to
+	// ~/.ssh/known_hosts.
+	KnownHostsFile string
+	// Passphrase is asked for the passphrase of an encrypted key file. When
+	// nil, encrypted keys are skipped.
+	Passphrase func(file string) (string, error)
+}
+
+// Tunnel is an open SSH connection to a bastion host.
+type Tunnel struct {
+	client *ssh.Client
+}
+
+// Open connects and authenticates to the bastion described by cfg.
+func Open(ctx context.Context, cfg Config) (*Tunnel, error) {
+	username, addr, err := ParseTarget(cfg.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	hostKeys, err := hostKeyCallback(cfg.KnownHostsFile)
+	if err != nil {
+		return nil, err
+	}
+	auth, closeAgent := authMethods(cfg)
+	defer closeAgent()
+
+	clientConfig := &ssh.ClientConfig{
+		User:            username,
+		Auth:            auth,
+		HostKeyCallback: hostKeys,
+		Timeout:         dialTimeout,
+	}
+
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("ssh: %w", err)
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, clientConfig)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("ssh %s: %w", addr, err)
+	}
+	return &Tunnel{client: ssh.NewClient(sshConn, chans, reqs)}, nil
+}
+
+// DialContext opens a connection to addr from the bastion. It has the
+// signature of a pgconn DialFunc.
+func (t *Tunnel) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	return t.client.DialContext(ctx, network, addr)
+}
+
+// Close closes the SSH connection and every connection dialed through it.
+func (t *Tunnel) Close() error {
+	return t.client.Close()
+}
+
+// ParseTarget splits a user@host[:port] target into the user and the
+// host:port address.
+func ParseTarget(target string) (string, string, error) {
+	username, host, found := strings.Cut(target, "@")
+	if !found {
+		host, username = target, ""
+	}
+	if username == "" {
+		if current, err := user.Current(); err == nil {
+			username = current.Username
+		}
+	}
+	if host == "" {
+		return "", "", fmt.Errorf("ssh target %q must be user@host[:port]", target)
+	}
+
+	port := defaultPort
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		host, port = h, p
+	}
+	return username, net.JoinHostPort(strings.Trim(host, "[]"), port), nil
+}
+
+// hostKeyCallback verifies host keys against the known_hosts file. Unknown
+// hosts are rejected; pgxcli never adds them to the file itself.
+func hostKeyCallback(file string) (ssh.HostKeyCallback, error) {
+	if file == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		file = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	callback, err := knownhosts.New(file)
+	if err != nil {
+		return nil, fmt.Errorf("ssh: read known hosts: %w", err)
+	}
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := callback(hostname, remote, key)
+		var keyErr *knownhosts.KeyError
+		if errors.As(err, &keyErr) && len(keyErr.Want) == 0 {
+			return fmt.Errorf("host key of %s is not in %s; connect once with ssh to verify and add it", hostname, file)
+		}
+		return err
+	}, nil
+}
+
+// authMethods returns the public key method, offering the keys of the SSH
+// agent, when SSH_AUTH_SOCK is set, followed by the key files, and a function
+// closing the agent connection. Both go in one method because a client never
+// tries a method name twice.
+func authMethods(cfg Config) ([]ssh.AuthMethod, func()) {
+	var agentSigners func() ([]ssh.Signer, error)
+	closeAgent := func() {}
+
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if conn, err := net.Dial("unix", sock); err == nil {
+			agentSigners = agent.NewClient(conn).Signers
+			closeAgent = func() { _ = conn.Close() }
+		}
+	}
+
+	signers := func() ([]ssh.Signer, error) {
+		var signers []ssh.Signer
+		if agentSigners != nil {
+			// an agent that cannot list its keys is skipped, as by ssh
+			if fromAgent, err := agentSigners(); err == nil {
+				signers = append(signers, fromAgent...)
+			}
+		}
+		fromFiles, err := keySigners(cfg)
+		if err != nil && len(signers) == 0 {
+			return nil, err
+		}
+		return append(signers, fromFiles...), nil
+	}
+	return []ssh.AuthMethod{ssh.PublicKeysCallback(signers)}, closeAgent
+}
+
+// keySigners loads the identity file, or the default keys that exist and
+// can be read.
+func keySigners(cfg Config) ([]ssh.Signer, error) {
+	if cfg.IdentityFile != "" {
+		signer, err := loadKey(cfg.IdentityFile, cfg.Passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("ssh: load identity %s: %w", cfg.IdentityFile, err)
+		}
+		return []ssh.Signer{signer}, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, nil
+	}
+	var signers []ssh.Signer
+	for _, name := range defaultIdentities {
+		if signer, err := loadKey(filepath.Join(home, ".ssh", name), cfg.Passphrase); err == nil {
+			signers = append(signers, signer)
+		}
+	}
+	return signers, nil
+}
+
+// loadKey reads a private key file. The passphrase of an encrypted key
+// whose public key is stored in the clear is only asked for once the server
+// accepts that key, as ssh does, so keys the agent already offered do not
+// prompt.
+func loadKey(file string, passphrase func(string) (string, error)) (ssh.Signer, error) {
+	pem, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := ssh.ParsePrivateKey(pem)
+	var missing *ssh.PassphraseMissingError
+	if !errors.As(err, &missing) || passphrase == nil {
+		return signer, err
+	}
+
+	unlock := func() (ssh.Signer, error) {
+		phrase, err := passphrase(file)
+		if err != nil {
+			return nil, err
+		}
+		return ssh.ParsePrivateKeyWithPassphrase(pem, []byte(phrase))
+	}
+	if missing.PublicKey == nil {
+		return unlock()
+	}
+	return &lockedKey{public: missing.PublicKey, unlock: unlock}, nil
+}
+
+// lockedKey is an encrypted private key, unlocked when it first signs.
+type lockedKey struct {
+	public ssh.PublicKey
+	unlock func() (ssh.Signer, error)
+	signer ssh.AlgorithmSigner
+}
+
+func (k *lockedKey) PublicKey() ssh.PublicKey {
+	return k.public
+}
+
+func (k *lockedKey) Sign(rand io.Reader, data []byte) (*ssh.Signature, error) {
+	return k.SignWithAlgorithm(rand, data, "")
+}
+
+func (k *lockedKey) SignWithAlgorithm(rand io.Reader, data []byte, algorithm string) (*ssh.Signature, error) {
+	if k.signer == nil {
+		signer, err := k.unlock()
+		if err != nil {
+			return nil, fmt.Errorf("ssh: unlock key: %w", err)
+		}
+		algorithmSigner, ok := signer.(ssh.AlgorithmSigner)
+		if !ok {
+			return nil, fmt.Errorf("ssh: key type %s cannot sign", signer.PublicKey().Type())
+		}
+		k.signer = algorithmSigner
+	}
+	return k.signer.SignWithAlgorithm(rand, data, algorithm)
+}
@@ -0,0 +1,371 @@
+package sshtunnel
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// bastion is an in-process SSH server that forwards direct-tcpip channels,
+// as a jump host does.
+type bastion struct {
+	addr    string
+	hostKey ssh.PublicKey
+}
+
+func startBastion(t *testing.T, authorized ssh.PublicKey) *bastion {
+	t.Helper()
+	_, hostPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	require.NoError(t, err)
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if bytes.Equal(key.Marshal(), authorized.Marshal()) {
+				return nil, nil
+			}
+			return nil, errors.New("unauthorized key")
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveSSH(conn, config)
+		}
+	}()
+	return &bastion{addr: ln.Addr().String(), hostKey: hostSigner.PublicKey()}
+}
+
+func serveSSH(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChan := range chans {
+		if newChan.ChannelType() != "direct-tcpip" {
+			_ = newChan.Reject(ssh.UnknownChannelType, "only direct-tcpip")
+			continue
+		}
+		var target struct {
+			Host     string
+			Port     uint32
+			OrigHost string
+			OrigPort uint32
+		}
+		if err := ssh.Unmarshal(newChan.ExtraData(), &target); err != nil {
+			_ = newChan.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+		upstream, err := net.Dial("tcp", net.JoinHostPort(target.Host, strconv.Itoa(int(target.Port))))
+		if err != nil {
+			_ = newChan.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+		channel, channelReqs, err := newChan.Accept()
+		if err != nil {
+			_ = upstream.Close()
+			continue
+		}
+		go ssh.DiscardRequests(channelReqs)
+		go func() {
+			_, _ = io.Copy(channel, upstream)
+			_ = channel.CloseWrite()
+		}()
+		go func() {
+			_, _ = io.Copy(upstream, channel)
+			_ = upstream.Close()
+		}()
+	}
+}
+
+// startEcho stands in for the database, which only the bastion can reach.
+func startEcho(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, _ = io.Copy(conn, conn)
+				_ = conn.Close()
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// isolate keeps the agent and keys of the user running the tests out of the way.
+func isolate(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SSH_AUTH_SOCK", "")
+	return home
+}
+
+func writeKnownHosts(t *testing.T, dir string, b *bastion) string {
+	t.Helper()
+	path := filepath.Join(dir, "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(b.addr)}, b.hostKey)
+	require.NoError(t, os.WriteFile(path, []byte(line+"\n"), 0o600))
+	return path
+}
+
+func writeKey(t *testing.T, dir string, priv ed25519.PrivateKey, passphrase string) string {
+	t.Helper()
+	var block *pem.Block
+	var err error
+	if passphrase == "" {
+		block, err = ssh.MarshalPrivateKey(priv, "")
+	} else {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(priv, "", []byte(passphrase))
+	}
+	require.NoError(t, err)
+	path := filepath.Join(dir, "id_test")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(block), 0o600))
+	return path
+}
+
+func newClientKey(t *testing.T) (ed25519.PrivateKey, ssh.PublicKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	sshPub, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+	return priv, sshPub
+}
+
+// assertTunnels checks that a connection dialed through tunnel reaches target.
+func assertTunnels(t *testing.T, tunnel *Tunnel, target string) {
+	t.Helper()
+	conn, err := tunnel.DialContext(context.Background(), "tcp", target)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(buf))
+}
+
+func TestOpen_KeyFile(t *testing.T) {
+	dir := isolate(t)
+	priv, pub := newClientKey(t)
+	b := startBastion(t, pub)
+	target := startEcho(t)
+
+	tunnel, err := Open(context.Background(), Config{
+		Target:         "app@" + b.addr,
+		IdentityFile:   writeKey(t, dir, priv, ""),
+		KnownHostsFile: writeKnownHosts(t, dir, b),
+	})
+	require.NoError(t, err)
+	defer tunnel.Close()
+
+	assertTunnels(t, tunnel, target)
+}
+
+func TestOpen_EncryptedKeyFile(t *testing.T) {
+	dir := isolate(t)
+	priv, pub := newClientKey(t)
+	b := startBastion(t, pub)
+	keyFile := writeKey(t, dir, priv, "hunter2")
+
+	var asked []string
+	tunnel, err := Open(context.Background(), Config{
+		Target:         "app@" + b.addr,
+		IdentityFile:   keyFile,
+		KnownHostsFile: writeKnownHosts(t, dir, b),
+		Passphrase: func(file string) (string, error) {
+			asked = append(asked, file)
+			return "hunter2", nil
+		},
+	})
+	require.NoError(t, err)
+	defer tunnel.Close()
+	assert.Equal(t, []string{keyFile}, asked)
+
+	_, err = Open(context.Background(), Config{
+		Target:         "app@" + b.addr,
+		IdentityFile:   keyFile,
+		KnownHostsFile: writeKnownHosts(t, dir, b),
+	})
+	assert.ErrorContains(t, err, "load identity")
+}
+
+// startAgent serves an SSH agent holding priv and points SSH_AUTH_SOCK at it.
+func startAgent(t *testing.T, priv ed25519.PrivateKey) {
+	t.Helper()
+	keyring := agent.NewKeyring()
+	require.NoError(t, keyring.Add(agent.AddedKey{PrivateKey: priv}))
+	// unix socket paths are short, so the socket does not go in t.TempDir
+	sockDir, err := os.MkdirTemp("", "agent")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(sockDir) })
+	sock := filepath.Join(sockDir, "agent.sock")
+	ln, err := net.Listen("unix", sock)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() { _ = agent.ServeAgent(keyring, conn) }()
+		}
+	}()
+	t.Setenv("SSH_AUTH_SOCK", sock)
+}
+
+func TestOpen_Agent(t *testing.T) {
+	dir := isolate(t)
+	priv, pub := newClientKey(t)
+	b := startBastion(t, pub)
+	target := startEcho(t)
+
+	startAgent(t, priv)
+
+	tunnel, err := Open(context.Background(), Config{
+		Target:         "app@" + b.addr,
+		KnownHostsFile: writeKnownHosts(t, dir, b),
+	})
+	require.NoError(t, err)
+	defer tunnel.Close()
+
+	assertTunnels(t, tunnel, target)
+}
+
+func TestOpen_KeyFileAfterRejectedAgentKey(t *testing.T) {
+	dir := isolate(t)
+	priv, pub := newClientKey(t)
+	b := startBastion(t, pub)
+	target := startEcho(t)
+	otherPriv, _ := newClientKey(t)
+	startAgent(t, otherPriv)
+
+	tunnel, err := Open(context.Background(), Config{
+		Target:         "app@" + b.addr,
+		IdentityFile:   writeKey(t, dir, priv, ""),
+		KnownHostsFile: writeKnownHosts(t, dir, b),
+	})
+	require.NoError(t, err)
+	defer tunnel.Close()
+
+	assertTunnels(t, tunnel, target)
+}
+
+func TestOpen_AgentKeyDoesNotUnlockKeyFile(t *testing.T) {
+	dir := isolate(t)
+	priv, pub := newClientKey(t)
+	b := startBastion(t, pub)
+	startAgent(t, priv)
+	otherPriv, _ := newClientKey(t)
+
+	tunnel, err := Open(context.Background(), Config{
+		Target:         "app@" + b.addr,
+		IdentityFile:   writeKey(t, dir, otherPriv, "hunter2"),
+		KnownHostsFile: writeKnownHosts(t, dir, b),
+		Passphrase: func(file string) (string, error) {
+			t.Errorf("asked for the passphrase of %s", file)
+			return "", errors.New("not asked")
+		},
+	})
+	require.NoError(t, err)
+	defer tunnel.Close()
+}
+
+func TestOpen_RejectsUnknownAndChangedHostKeys(t *testing.T) {
+	dir := isolate(t)
+	priv, pub := newClientKey(t)
+	b := startBastion(t, pub)
+	keyFile := writeKey(t, dir, priv, "")
+
+	empty := filepath.Join(dir, "empty_known_hosts")
+	require.NoError(t, os.WriteFile(empty, nil, 0o600))
+	_, err := Open(context.Background(), Config{Target: "app@" + b.addr, IdentityFile: keyFile, KnownHostsFile: empty})
+	assert.ErrorContains(t, err, "is not in "+empty)
+
+	other := startBastion(t, pub)
+	changed := filepath.Join(dir, "changed_known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(b.addr)}, other.hostKey)
+	require.NoError(t, os.WriteFile(changed, []byte(line+"\n"), 0o600))
+	_, err = Open(context.Background(), Config{Target: "app@" + b.addr, IdentityFile: keyFile, KnownHostsFile: changed})
+	assert.ErrorContains(t, err, "key mismatch")
+}
+
+func TestOpen_RejectsUnauthorizedKey(t *testing.T) {
+	dir := isolate(t)
+	_, pub := newClientKey(t)
+	b := startBastion(t, pub)
+	otherPriv, _ := newClientKey(t)
+
+	_, err := Open(context.Background(), Config{
+		Target:         "app@" + b.addr,
+		IdentityFile:   writeKey(t, dir, otherPriv, ""),
+		KnownHostsFile: writeKnownHosts(t, dir, b),
+	})
+	assert.ErrorContains(t, err, "unable to authenticate")
+}
+
+func TestParseTarget(t *testing.T) {
+	testCases := []struct {
+		name     string
+		target   string
+		wantUser string
+		wantAddr string
+		wantErr  bool
+	}{
+		{name: "user and host", target: "deploy@bastion.example.com", wantUser: "deploy", wantAddr: "bastion.example.com:22"},
+		{name: "with port", target: "deploy@bastion:2222", wantUser: "deploy", wantAddr: "bastion:2222"},
+		{name: "ipv6", target: "deploy@[fd00::1]:2222", wantUser: "deploy", wantAddr: "[fd00::1]:2222"},
+		{name: "ipv6 without port", target: "deploy@[fd00::1]", wantUser: "deploy", wantAddr: "[fd00::1]:22"},
+		{name: "no host", target: "deploy@", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			user, addr, err := ParseTarget(tc.target)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantUser, user)
+			assert.Equal(t, tc.wantAddr, addr)
+		})
+	}
+}
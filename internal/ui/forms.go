@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"image/color"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -59,7 +60,10 @@ func newStyles(hasDarkBg bool) *styles {
 	return &s
 }
 
-var errFormAborted = errors.New("connection form aborted")
+var (
+	errFormAborted = errors.New("connection form aborted")
+	profileName    = regexp.MustCompile(`^[A-Za-z0-9_-]*$`)
+)
 
 // ConnectionValues contains connection details collected from the interactive form.
 type ConnectionValues struct {
@@ -68,6 +72,17 @@ type ConnectionValues struct {
 	Port     string
 	Host     string
 	Password string
+
+	// Profile is the connection profile picked before the form, if any.
+	Profile string
+	// SaveAs is the profile name the entered values should be saved under.
+	SaveAs string
+}
+
+// Profile is a saved connection offered by the picker ahead of the form.
+type Profile struct {
+	Name   string
+	Values ConnectionValues
 }
 
 type model struct {
@@ -125,6 +140,12 @@ func newModel(database, username, host, port string) *model {
 				Title("Password").
 				EchoMode(huh.EchoModePassword).
 				Value(&m.values.Password),
+			huh.NewInput().
+				Key("save_as").
+				Title("Save as profile").
+				Description("skipped if empty").
+				Validate(validateProfileName).
+				Value(&m.values.SaveAs),
 		),
 	).
 		WithWidth(45).
@@ -182,6 +203,9 @@ func (m *model) View() tea.View {
 		fmt.Fprintf(&b, "Port: %s\n", fallback(m.values.Port))
 		fmt.Fprintf(&b, "Host: %s\n", fallback(m.values.Host))
 		fmt.Fprintf(&b, "Password: %s", maskedPassword(m.values.Password))
+		if m.values.SaveAs != "" {
+			fmt.Fprintf(&b, "\nSaved as: %s", m.values.SaveAs)
+		}
 		rendered := s.Status.Margin(0, 1).Padding(1, 2).Width(48).Render(b.String())
 		view := tea.NewView(lipgloss.Place(m.termWidth, m.height, lipgloss.Center, lipgloss.Center, rendered))
 		view.AltScreen = true
@@ -282,11 +306,24 @@ func (m *model) result() (ConnectionValues, error) {
 	}
 
 	values := m.values
+	values.SaveAs = strings.TrimSpace(values.SaveAs)
 	return values, nil
 }
 
 // RunConnectionForm starts the interactive connection form and returns collected values.
-func RunConnectionForm(database, username, host, port string) (ConnectionValues, error) {
+// When profiles are given, a picker runs first and the chosen profile fills the form.
+func RunConnectionForm(database, username, host, port string, profiles []Profile) (ConnectionValues, error) {
+	picked, err := pickProfile(profiles)
+	if err != nil {
+		return ConnectionValues{}, err
+	}
+	if picked != nil {
+		database = firstNonBlank(picked.Values.Database, database)
+		username = firstNonBlank(picked.Values.Username, username)
+		host = firstNonBlank(picked.Values.Host, host)
+		port = firstNonBlank(picked.Values.Port, port)
+	}
+
 	finalModel, err := tea.NewProgram(newModel(database, username, host, port)).Run()
 	if err != nil {
 		return ConnectionValues{}, err
@@ -297,7 +334,63 @@ func RunConnectionForm(database, username, host, port string) (ConnectionValues,
 		return ConnectionValues{}, fmt.Errorf("unexpected model type: %T", finalModel)
 	}
 
-	return m.result()
+	values, err := m.result()
+	if err != nil {
+		return ConnectionValues{}, err
+	}
+	if picked != nil {
+		values.Profile = picked.Name
+	}
+	return values, nil
+}
+
+// pickProfile asks which profile to start from. It returns nil for a new connection.
+func pickProfile(profiles []Profile) (*Profile, error) {
+	if len(profiles) == 0 {
+		return nil, nil
+	}
+
+	choice := -1
+	options := []huh.Option[int]{huh.NewOption("New connection", -1)}
+	for i, p := range profiles {
+		options = append(options, huh.NewOption(profileLabel(p), i))
+	}
+
+	err := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[int]().
+				Title("Connection profile").
+				Options(options...).
+				Value(&choice),
+		),
+	).
+		WithShowHelp(false).
+		Run()
+	if errors.Is(err, huh.ErrUserAborted) {
+		return nil, errFormAborted
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if choice < 0 {
+		return nil, nil
+	}
+	return &profiles[choice], nil
+}
+
+func profileLabel(p Profile) string {
+	v := p.Values
+	return fmt.Sprintf("%s  %s", p.Name, buildDSNPreview(v.Database, v.Username, v.Host, v.Port))
+}
+
+func firstNonBlank(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
 }
 
 func fallback(value string) string {
@@ -331,6 +424,13 @@ func buildDSNPreview(db, user, host, port string) string {
 	return fmt.Sprintf("postgresql://%s@%s:%s/%s", user, host, port, db)
 }
 
+func validateProfileName(v string) error {
+	if !profileName.MatchString(strings.TrimSpace(v)) {
+		return errors.New("profile name may only contain letters, digits, '-' and '_'")
+	}
+	return nil
+}
+
 func validatePort(v string) error {
 	v = strings.TrimSpace(v)
 	if v == "" {
@@ -167,3 +167,55 @@ func TestValidatePort(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateProfileName(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		in      string
+		wantErr bool
+	}{
+		{name: "empty skips saving", in: ""},
+		{name: "simple", in: "prod"},
+		{name: "dashes and underscores", in: "eu-west_1"},
+		{name: "surrounding spaces", in: " prod "},
+		{name: "dot", in: "prod.eu", wantErr: true},
+		{name: "space inside", in: "my prod", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := validateProfileName(tc.in)
+			if tc.wantErr {
+				assert.EqualError(t, err, "profile name may only contain letters, digits, '-' and '_'")
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestResult_TrimsSaveAs(t *testing.T) {
+	t.Parallel()
+
+	m := newModel("postgres", "user", "localhost", "5432")
+	m.values.SaveAs = "  local "
+	m.form.State = huh.StateCompleted
+
+	got, err := m.result()
+	require.NoError(t, err)
+	assert.Equal(t, "local", got.SaveAs)
+}
+
+func TestProfileLabel(t *testing.T) {
+	t.Parallel()
+
+	p := Profile{
+		Name:   "prod",
+		Values: ConnectionValues{Database: "app", Username: "readonly", Host: "db.example.com", Port: "6432"},
+	}
+	assert.Equal(t, "prod  postgresql://readonly@db.example.com:6432/app", profileLabel(p))
+}